@@ -0,0 +1,162 @@
+package base
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+	SeverityNote
+	SeverityHint
+)
+
+func (severity Severity) String() string {
+	switch severity {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	case SeverityNote:
+		return "note"
+	case SeverityHint:
+		return "hint"
+	}
+	return fmt.Sprintf("Severity(%d)", int(severity))
+}
+
+// Structured error or message about the source code.
+//
+// The Code is a stable identifier for the kind of diagnostic, independent
+// of the message text. Related spans point to other locations relevant to
+// the diagnostic, such as a previous declaration.
+type Diagnostic struct {
+	Severity Severity
+	Code     string
+	Message  string
+	Span     Span
+	Related  []Related
+}
+
+type Related struct {
+	Span    Span
+	Message string
+}
+
+// Errors that can be described as a Diagnostic.
+type DiagnosticError interface {
+	error
+	Diagnostic() *Diagnostic
+}
+
+func (diag *Diagnostic) Error() string {
+	out := strings.Builder{}
+	if diag.Span.Valid() {
+		out.WriteString(diag.Span.String())
+		out.WriteString(": ")
+	}
+	if diag.Severity != SeverityError {
+		out.WriteString(diag.Severity.String())
+		out.WriteString(": ")
+	}
+	out.WriteString(diag.Message)
+	for _, it := range diag.Related {
+		out.WriteString("\n    ")
+		if it.Span.Valid() {
+			out.WriteString(it.Span.String())
+			out.WriteString(": ")
+		}
+		out.WriteString(it.Message)
+	}
+	return out.String()
+}
+
+func (diag *Diagnostic) Diagnostic() *Diagnostic {
+	return diag
+}
+
+// Returns the error as a Diagnostic. Errors that do not provide one are
+// reported as an error severity diagnostic with the error message.
+func AsDiagnostic(err error) *Diagnostic {
+	var diag DiagnosticError
+	if errors.As(err, &diag) {
+		return diag.Diagnostic()
+	}
+	return &Diagnostic{Severity: SeverityError, Message: err.Error()}
+}
+
+// Compares the diagnostics by location, then by severity, code and message.
+// Diagnostics without a location sort after the ones with it.
+func (diag *Diagnostic) Less(other *Diagnostic) bool {
+	a, b := diag.Span, other.Span
+	if a.Valid() != b.Valid() {
+		return a.Valid()
+	}
+	if a.File != b.File {
+		return a.File < b.File
+	}
+	if c := a.Sta.Compare(b.Sta); c != 0 {
+		return c < 0
+	}
+	if c := a.End.Compare(b.End); c != 0 {
+		return c < 0
+	}
+	if diag.Severity != other.Severity {
+		return diag.Severity < other.Severity
+	}
+	if diag.Code != other.Code {
+		return diag.Code < other.Code
+	}
+	return diag.Message < other.Message
+}
+
+func (pos Pos) Compare(other Pos) int {
+	if pos.Line != other.Line {
+		return pos.Line - other.Line
+	}
+	return pos.Column - other.Column
+}
+
+// Returns true if the set has any entry with error severity. Entries that
+// are not a Diagnostic count as errors.
+func (set *ErrorSet) HasErrors() bool {
+	return set.Count(SeverityError) > 0
+}
+
+// Returns the number of entries with the given severity.
+func (set *ErrorSet) Count(severity Severity) (count int) {
+	set.sync.RLock()
+	defer set.sync.RUnlock()
+	for _, it := range set.list {
+		if AsDiagnostic(it).Severity == severity {
+			count++
+		}
+	}
+	return count
+}
+
+// Returns all entries as diagnostics, in the set order.
+func (set *ErrorSet) Diagnostics() (out []*Diagnostic) {
+	set.sync.RLock()
+	defer set.sync.RUnlock()
+	for _, it := range set.list {
+		out = append(out, AsDiagnostic(it))
+	}
+	return out
+}
+
+// Sorts the entries by file and position. The order is deterministic
+// regardless of the order the entries were added, except for entries with
+// the same location and text.
+func (set *ErrorSet) Sort() {
+	set.sync.Lock()
+	defer set.sync.Unlock()
+	sort.SliceStable(set.list, func(a, b int) bool {
+		return AsDiagnostic(set.list[a]).Less(AsDiagnostic(set.list[b]))
+	})
+}
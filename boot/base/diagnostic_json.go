@@ -0,0 +1,225 @@
+package base
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// JSON form for a diagnostic. Spans are omitted when not valid.
+type diagnosticJSON struct {
+	Severity Severity      `json:"severity"`
+	Code     string        `json:"code,omitempty"`
+	Message  string        `json:"message"`
+	Span     *spanJSON     `json:"span,omitempty"`
+	Related  []relatedJSON `json:"related,omitempty"`
+}
+
+type relatedJSON struct {
+	Message string    `json:"message"`
+	Span    *spanJSON `json:"span,omitempty"`
+}
+
+type spanJSON struct {
+	File  string   `json:"file"`
+	Start posJSON  `json:"start"`
+	End   *posJSON `json:"end,omitempty"`
+}
+
+type posJSON struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+func (severity Severity) MarshalText() ([]byte, error) {
+	switch severity {
+	case SeverityError, SeverityWarning, SeverityNote, SeverityHint:
+		return []byte(severity.String()), nil
+	}
+	return nil, fmt.Errorf("invalid severity %d", int(severity))
+}
+
+func (severity *Severity) UnmarshalText(text []byte) error {
+	for _, it := range []Severity{SeverityError, SeverityWarning, SeverityNote, SeverityHint} {
+		if string(text) == it.String() {
+			*severity = it
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid severity `%s`", text)
+}
+
+func newSpanJSON(span Span) *spanJSON {
+	if !span.Valid() {
+		return nil
+	}
+	out := &spanJSON{File: span.File, Start: posJSON(span.Sta)}
+	if span.End.Line > 0 {
+		out.End = (*posJSON)(&span.End)
+	}
+	return out
+}
+
+func (span *spanJSON) span() (out Span) {
+	if span != nil {
+		out = Span{File: span.File, Sta: Pos(span.Start)}
+		if span.End != nil {
+			out.End = Pos(*span.End)
+		}
+	}
+	return out
+}
+
+// Encodes the set as a JSON object with a `diagnostics` list.
+func (set *ErrorSet) MarshalJSON() ([]byte, error) {
+	out := struct {
+		Diagnostics []diagnosticJSON `json:"diagnostics"`
+	}{Diagnostics: []diagnosticJSON{}}
+
+	for _, diag := range set.Diagnostics() {
+		item := diagnosticJSON{
+			Severity: diag.Severity,
+			Code:     diag.Code,
+			Message:  diag.Message,
+			Span:     newSpanJSON(diag.Span),
+		}
+		for _, it := range diag.Related {
+			item.Related = append(item.Related, relatedJSON{Message: it.Message, Span: newSpanJSON(it.Span)})
+		}
+		out.Diagnostics = append(out.Diagnostics, item)
+	}
+	return json.Marshal(out)
+}
+
+// Decodes diagnostics encoded by MarshalJSON, adding them to the set.
+func (set *ErrorSet) UnmarshalJSON(data []byte) error {
+	var input struct {
+		Diagnostics []diagnosticJSON `json:"diagnostics"`
+	}
+	if err := json.Unmarshal(data, &input); err != nil {
+		return err
+	}
+
+	for _, it := range input.Diagnostics {
+		diag := &Diagnostic{
+			Severity: it.Severity,
+			Code:     it.Code,
+			Message:  it.Message,
+			Span:     it.Span.span(),
+		}
+		for _, rel := range it.Related {
+			diag.Related = append(diag.Related, Related{Span: rel.Span.span(), Message: rel.Message})
+		}
+		set.Add(diag)
+	}
+	return nil
+}
+
+// Writes the set as a SARIF 2.1.0 log with a single run for the tool.
+//
+// Diagnostic codes are reported as rule ids. Hints have the `none` level,
+// as SARIF has no separate level for them.
+func (set *ErrorSet) WriteSARIF(output io.Writer, tool, version string) error {
+	type message struct {
+		Text string `json:"text"`
+	}
+	type region struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn"`
+		EndLine     int `json:"endLine,omitempty"`
+		EndColumn   int `json:"endColumn,omitempty"`
+	}
+	type physicalLocation struct {
+		ArtifactLocation struct {
+			URI string `json:"uri"`
+		} `json:"artifactLocation"`
+		Region region `json:"region"`
+	}
+	type location struct {
+		Id               *int             `json:"id,omitempty"`
+		PhysicalLocation physicalLocation `json:"physicalLocation"`
+		Message          *message         `json:"message,omitempty"`
+	}
+	type result struct {
+		RuleId           string     `json:"ruleId,omitempty"`
+		Level            string     `json:"level"`
+		Message          message    `json:"message"`
+		Locations        []location `json:"locations,omitempty"`
+		RelatedLocations []location `json:"relatedLocations,omitempty"`
+	}
+	type rule struct {
+		Id string `json:"id"`
+	}
+
+	locationOf := func(span Span) location {
+		out := location{}
+		out.PhysicalLocation.ArtifactLocation.URI = span.File
+		out.PhysicalLocation.Region = region{StartLine: span.Sta.Line, StartColumn: span.Sta.Column}
+		if span.End.Line > 0 {
+			out.PhysicalLocation.Region.EndLine = span.End.Line
+			out.PhysicalLocation.Region.EndColumn = span.End.Column
+		}
+		return out
+	}
+
+	levels := map[Severity]string{
+		SeverityError:   "error",
+		SeverityWarning: "warning",
+		SeverityNote:    "note",
+		SeverityHint:    "none",
+	}
+
+	rules, results := []rule{}, []result{}
+	hasRule := make(map[string]bool)
+	for _, diag := range set.Diagnostics() {
+		item := result{
+			RuleId:  diag.Code,
+			Level:   levels[diag.Severity],
+			Message: message{Text: diag.Message},
+		}
+		if diag.Span.Valid() {
+			item.Locations = []location{locationOf(diag.Span)}
+		}
+		for n, it := range diag.Related {
+			if it.Span.Valid() {
+				id := n
+				loc := locationOf(it.Span)
+				loc.Id, loc.Message = &id, &message{Text: it.Message}
+				item.RelatedLocations = append(item.RelatedLocations, loc)
+			}
+		}
+		results = append(results, item)
+
+		if diag.Code != "" && !hasRule[diag.Code] {
+			hasRule[diag.Code] = true
+			rules = append(rules, rule{Id: diag.Code})
+		}
+	}
+
+	type driver struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+		Rules   []rule `json:"rules"`
+	}
+	type run struct {
+		Tool struct {
+			Driver driver `json:"driver"`
+		} `json:"tool"`
+		Results []result `json:"results"`
+	}
+
+	log := struct {
+		Schema  string `json:"$schema"`
+		Version string `json:"version"`
+		Runs    []run  `json:"runs"`
+	}{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []run{{Results: results}},
+	}
+	log.Runs[0].Tool.Driver = driver{Name: tool, Version: version, Rules: rules}
+
+	encoder := json.NewEncoder(output)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(log)
+}
@@ -0,0 +1,188 @@
+package base_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"axlab.dev/bit/base"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiagnostic(t *testing.T) {
+	test := require.New(t)
+
+	at := func(line, column int) base.Span {
+		return base.Span{File: "main.bit", Sta: base.Pos{Line: line, Column: column}}
+	}
+
+	diag := &base.Diagnostic{
+		Severity: base.SeverityWarning,
+		Code:     "W0001",
+		Message:  "`x` shadows an outer declaration",
+		Span:     at(3, 5),
+		Related:  []base.Related{{Span: at(1, 1), Message: "shadowed declaration"}},
+	}
+	test.Equal("main.bit:3:5: warning: `x` shadows an outer declaration\n    main.bit:1:1: shadowed declaration", diag.Error())
+	test.Equal("error", base.SeverityError.String())
+	test.Equal("hint", base.SeverityHint.String())
+
+	plain := base.AsDiagnostic(base.Error("some error"))
+	test.Equal(base.SeverityError, plain.Severity)
+	test.Equal("some error", plain.Message)
+	test.Same(diag, base.AsDiagnostic(diag))
+}
+
+func TestErrorSetSeverity(t *testing.T) {
+	test := require.New(t)
+
+	set := base.ErrorSet{}
+	set.Add(&base.Diagnostic{Severity: base.SeverityWarning, Message: "warning"})
+	set.Add(&base.Diagnostic{Severity: base.SeverityNote, Message: "note"})
+	test.False(set.HasErrors())
+	test.Equal(2, set.Len())
+	test.Equal(1, set.Count(base.SeverityWarning))
+
+	set.Add(base.Error("plain error"))
+	test.True(set.HasErrors())
+	test.Equal(1, set.Count(base.SeverityError))
+
+	set.Add(&base.Diagnostic{Severity: base.SeverityWarning, Message: "other warning"})
+	test.Equal("1 error, 2 warnings, 1 note:\n[1] warning: warning\n[2] note: note\n[3] plain error\n[4] warning: other warning", set.String())
+}
+
+func TestErrorSetSort(t *testing.T) {
+	test := require.New(t)
+
+	span := func(file string, line, column int) base.Span {
+		return base.Span{File: file, Sta: base.Pos{Line: line, Column: column}}
+	}
+
+	list := []error{
+		base.Error("no location"),
+		&base.Diagnostic{Message: "b 2:1", Span: span("b.bit", 2, 1)},
+		&base.Diagnostic{Message: "a 10:1", Span: span("a.bit", 10, 1)},
+		&base.Diagnostic{Severity: base.SeverityWarning, Message: "a 2:3 warning", Span: span("a.bit", 2, 3)},
+		&base.Diagnostic{Message: "a 2:3 error", Span: span("a.bit", 2, 3)},
+		&base.Diagnostic{Message: "a 2:1", Span: span("a.bit", 2, 1)},
+	}
+
+	expected := []string{"a 2:1", "a 2:3 error", "a 2:3 warning", "a 10:1", "b 2:1", "no location"}
+	for _, order := range [][]int{{0, 1, 2, 3, 4, 5}, {5, 4, 3, 2, 1, 0}, {3, 0, 5, 1, 4, 2}} {
+		set := base.ErrorSet{}
+		for _, n := range order {
+			set.Add(list[n])
+		}
+		set.Sort()
+
+		var messages []string
+		for _, it := range set.Diagnostics() {
+			messages = append(messages, it.Message)
+		}
+		test.Equal(expected, messages)
+	}
+}
+
+func TestErrorSetJSON(t *testing.T) {
+	test := require.New(t)
+
+	set := base.ErrorSet{}
+	set.Add(
+		&base.Diagnostic{
+			Severity: base.SeverityError,
+			Code:     "E0001",
+			Message:  "`x` is already declared",
+			Span: base.Span{
+				File: "src/main.bit",
+				Sta:  base.Pos{Line: 2, Column: 5},
+				End:  base.Pos{Line: 2, Column: 6},
+			},
+			Related: []base.Related{{
+				Span:    base.Span{File: "src/main.bit", Sta: base.Pos{Line: 1, Column: 5}},
+				Message: "first declared here",
+			}},
+		},
+		&base.Diagnostic{Severity: base.SeverityWarning, Code: "W0002", Message: "unused variable `a`", Span: base.Span{File: "lib.bit", Sta: base.Pos{Line: 9, Column: 1}}},
+		&base.Diagnostic{Severity: base.SeverityNote, Message: "note without location"},
+		&base.Diagnostic{Severity: base.SeverityHint, Code: "H0001", Message: "a hint"},
+	)
+
+	data, err := json.Marshal(&set)
+	test.NoError(err)
+	test.Contains(string(data), `"severity":"warning"`)
+	test.Contains(string(data), `"span":{"file":"src/main.bit","start":{"line":2,"column":5},"end":{"line":2,"column":6}}`)
+
+	decoded := base.ErrorSet{}
+	test.NoError(json.Unmarshal(data, &decoded))
+	test.Equal(set.Diagnostics(), decoded.Diagnostics())
+	test.True(decoded.HasErrors())
+
+	test.Error(json.Unmarshal([]byte(`{"diagnostics":[{"severity":"fatal","message":"x"}]}`), &base.ErrorSet{}))
+
+	empty, err := json.Marshal(&base.ErrorSet{})
+	test.NoError(err)
+	test.Equal(`{"diagnostics":[]}`, string(empty))
+}
+
+func TestErrorSetSARIF(t *testing.T) {
+	test := require.New(t)
+
+	set := base.ErrorSet{}
+	set.Add(
+		&base.Diagnostic{
+			Code:    "E0001",
+			Message: "`x` is already declared",
+			Span:    base.Span{File: "main.bit", Sta: base.Pos{Line: 2, Column: 5}},
+			Related: []base.Related{{Span: base.Span{File: "main.bit", Sta: base.Pos{Line: 1, Column: 5}}, Message: "first declared here"}},
+		},
+		&base.Diagnostic{Severity: base.SeverityHint, Message: "a hint"},
+	)
+
+	out := strings.Builder{}
+	test.NoError(set.WriteSARIF(&out, "bit", "1.0.0"))
+
+	var log struct {
+		Version string
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Name  string
+					Rules []struct{ Id string }
+				}
+			}
+			Results []struct {
+				RuleId    string
+				Level     string
+				Message   struct{ Text string }
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct{ URI string }
+						Region           struct{ StartLine, StartColumn int }
+					}
+				}
+				RelatedLocations []struct {
+					Message struct{ Text string }
+				}
+			}
+		}
+	}
+	test.NoError(json.Unmarshal([]byte(out.String()), &log))
+	test.Equal("2.1.0", log.Version)
+	test.Len(log.Runs, 1)
+
+	run := log.Runs[0]
+	test.Equal("bit", run.Tool.Driver.Name)
+	test.Len(run.Tool.Driver.Rules, 1)
+	test.Equal("E0001", run.Tool.Driver.Rules[0].Id)
+
+	test.Len(run.Results, 2)
+	test.Equal("E0001", run.Results[0].RuleId)
+	test.Equal("error", run.Results[0].Level)
+	test.Equal("`x` is already declared", run.Results[0].Message.Text)
+	test.Equal("main.bit", run.Results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	test.Equal(2, run.Results[0].Locations[0].PhysicalLocation.Region.StartLine)
+	test.Equal(5, run.Results[0].Locations[0].PhysicalLocation.Region.StartColumn)
+	test.Equal("first declared here", run.Results[0].RelatedLocations[0].Message.Text)
+	test.Equal("none", run.Results[1].Level)
+	test.Empty(run.Results[1].Locations)
+}
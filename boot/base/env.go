@@ -7,20 +7,9 @@ import (
 )
 
 var projectDir = (func() string {
-	dir, valid := filepath.Dir(CurrentFile()), false
-	for {
-		if IsFile(filepath.Join(dir, "boot/cmd/bit.go")) && IsFile(filepath.Join(dir, "go.work")) {
-			valid = true
-			break
-		} else {
-			next := filepath.Dir(dir)
-			if next == dir || next == "." {
-				break
-			} else {
-				dir = next
-			}
-		}
-	}
+	dir, valid := FindDir(filepath.Dir(CurrentFile()), func(dir string) bool {
+		return IsFile(filepath.Join(dir, "boot/cmd/bit.go")) && IsFile(filepath.Join(dir, "go.work"))
+	})
 	if !valid {
 		panic("could not find bootstrap project dir")
 	}
@@ -59,3 +48,19 @@ func IsFile(path string) bool {
 	}
 	return false
 }
+
+// Walks up from the start directory to the file system root, returning the
+// first directory for which match is true.
+func FindDir(start string, match func(dir string) bool) (dir string, found bool) {
+	dir = filepath.Clean(start)
+	for {
+		if match(dir) {
+			return dir, true
+		}
+		next := filepath.Dir(dir)
+		if next == dir || next == "." {
+			return "", false
+		}
+		dir = next
+	}
+}
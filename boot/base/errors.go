@@ -88,8 +88,25 @@ func (set *ErrorSet) String() string {
 		return ""
 	}
 
+	counts := make(map[Severity]int)
+	for _, it := range set.list {
+		counts[AsDiagnostic(it).Severity]++
+	}
+
+	// e.g. `2 errors, 1 warning:`
+	var summary []string
+	for _, severity := range []Severity{SeverityError, SeverityWarning, SeverityNote, SeverityHint} {
+		switch count := counts[severity]; count {
+		case 0:
+		case 1:
+			summary = append(summary, fmt.Sprintf("1 %s", severity))
+		default:
+			summary = append(summary, fmt.Sprintf("%d %ss", count, severity))
+		}
+	}
+
 	out := strings.Builder{}
-	out.WriteString(fmt.Sprintf("there were %d errors:", len(set.list)))
+	out.WriteString(strings.Join(summary, ", ") + ":")
 
 	for n, it := range set.list {
 		out.WriteString(fmt.Sprintf("\n[%d] %s", n+1, it.Error()))
@@ -0,0 +1,41 @@
+package base
+
+import "fmt"
+
+// Location of a range of source text.
+//
+// Line and column numbers start at one, with columns counted in runes.
+// The end position is exclusive.
+type Span struct {
+	File string
+	Sta  Pos
+	End  Pos
+}
+
+type Pos struct {
+	Line   int
+	Column int
+}
+
+func (span Span) Valid() bool {
+	return span.Sta.Line > 0
+}
+
+func (span Span) String() string {
+	if !span.Valid() {
+		if span.File != "" {
+			return span.File
+		}
+		return "(unknown)"
+	}
+
+	file := span.File
+	if file == "" {
+		file = "(source)"
+	}
+	return fmt.Sprintf("%s:%d:%d", file, span.Sta.Line, span.Sta.Column)
+}
+
+func (pos Pos) String() string {
+	return fmt.Sprintf("%d:%d", pos.Line, pos.Column)
+}
@@ -0,0 +1,22 @@
+package base_test
+
+import (
+	"testing"
+
+	"axlab.dev/bit/base"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpan(t *testing.T) {
+	test := require.New(t)
+
+	span := base.Span{File: "main.bit", Sta: base.Pos{Line: 3, Column: 5}, End: base.Pos{Line: 3, Column: 9}}
+	test.True(span.Valid())
+	test.Equal("main.bit:3:5", span.String())
+	test.Equal("3:9", span.End.String())
+
+	test.False(base.Span{}.Valid())
+	test.Equal("(unknown)", base.Span{}.String())
+	test.Equal("main.bit", base.Span{File: "main.bit"}.String())
+	test.Equal("(source):1:2", base.Span{Sta: base.Pos{Line: 1, Column: 2}}.String())
+}
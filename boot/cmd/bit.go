@@ -1,11 +1,87 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"io"
+	"os"
 
 	"axlab.dev/bit/base"
 )
 
+const diagnosticsUsage = "output format for diagnostics: text, json or sarif"
+
 func main() {
-	fmt.Printf("\nBit version %s\n\n", base.Version())
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// Runs the command line, returning the exit status.
+//
+// The diagnostics format can be given before or after the command name.
+// Commands reject any argument they do not use.
+func run(args []string, stdout, stderr io.Writer) int {
+	global := flag.NewFlagSet("bit", flag.ContinueOnError)
+	global.SetOutput(stderr)
+	diagnostics := global.String("diagnostics", "text", diagnosticsUsage)
+	if err := global.Parse(args); err != nil {
+		return 2
+	}
+
+	command, args := global.Arg(0), global.Args()
+	if len(args) > 0 {
+		args = args[1:]
+	}
+
+	switch command {
+	case "explain":
+		return explain(args, stdout, stderr)
+	case "init":
+		return initProject(args, stdout, stderr)
+	case "", "run", "build", "test", "vendor":
+	default:
+		fmt.Fprintf(stderr, "error: unknown command `%s`\n", command)
+		return 2
+	}
+
+	flags := flag.NewFlagSet(command, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.StringVar(diagnostics, "diagnostics", *diagnostics, diagnosticsUsage)
+	update := false
+	if command == "vendor" {
+		flags.BoolVar(&update, "update", false, "accept dependencies with changed content")
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	} else if flags.NArg() > 0 {
+		fmt.Fprintf(stderr, "error: unexpected arguments for `%s`: %v\n", command, flags.Args())
+		return 2
+	}
+
+	format, err := parseDiagnosticsFormat(*diagnostics)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 2
+	}
+
+	if format == diagnosticsText {
+		fmt.Fprintf(stdout, "\nBit version %s\n\n", base.Version())
+	}
+
+	errs := base.ErrorSet{}
+	switch command {
+	case "run", "build", "test":
+		checkProject(command, &errs)
+	case "vendor":
+		vendorProject(update, &errs)
+	}
+
+	if err := writeDiagnostics(&errs, format, stdout, stderr); err != nil {
+		fmt.Fprintf(stderr, "error: writing diagnostics: %v\n", err)
+		return 2
+	}
+
+	if errs.HasErrors() {
+		return 1
+	}
+	return 0
 }
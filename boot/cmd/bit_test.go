@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"axlab.dev/bit/base"
+	"axlab.dev/bit/code"
+	"axlab.dev/bit/project"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiagnosticsAfterCommand(t *testing.T) {
+	test := require.New(t)
+	inProject(t)
+
+	for _, args := range [][]string{
+		{"run", "--diagnostics=json"},
+		{"--diagnostics=json", "run"},
+	} {
+		stdout, stderr := strings.Builder{}, strings.Builder{}
+		test.Equal(1, run(args, &stdout, &stderr))
+		test.Empty(stderr.String())
+
+		var out struct {
+			Diagnostics []struct {
+				Severity string `json:"severity"`
+				Message  string `json:"message"`
+			} `json:"diagnostics"`
+		}
+		test.NoError(json.Unmarshal([]byte(stdout.String()), &out), "stdout: %s", stdout.String())
+		test.Len(out.Diagnostics, 1)
+		test.Equal("error", out.Diagnostics[0].Severity)
+		test.Equal("`bit run` is not implemented yet: the project `app` is valid, but source files cannot be parsed", out.Diagnostics[0].Message)
+	}
+}
+
+func TestNoTestModules(t *testing.T) {
+	test := require.New(t)
+	inProject(t)
+
+	stdout, stderr := strings.Builder{}, strings.Builder{}
+	test.Equal(1, run([]string{"test"}, &stdout, &stderr))
+	test.Contains(stderr.String(), "`bit test` is not implemented yet")
+
+	test.NoError(os.Remove(filepath.Join("src", "greet_test.bit")))
+	stderr.Reset()
+	test.Equal(1, run([]string{"test"}, &stdout, &stderr))
+	test.Contains(stderr.String(), "no test modules found, test module names end with `_test`")
+}
+
+func TestRuntimeErrorDiagnostics(t *testing.T) {
+	test := require.New(t)
+
+	at := func(line int) base.Span {
+		return base.Span{File: "main.bit", Sta: base.Pos{Line: line, Column: 5}}
+	}
+	errs := &base.ErrorSet{}
+	errs.Add(&code.RuntimeError{
+		Err:   &base.Diagnostic{Code: "E0048", Message: "index out of bounds: index is 5 but length is 2"},
+		Stack: []code.StackEntry{{Func: "get", Span: at(2)}, {Span: at(9)}},
+	})
+
+	stdout, stderr := strings.Builder{}, strings.Builder{}
+	test.NoError(writeDiagnostics(errs, diagnosticsText, &stdout, &stderr))
+	test.Equal("main.bit:2:5: index out of bounds: index is 5 but length is 2\n    main.bit:9:5: called from program\n", stderr.String())
+
+	stdout.Reset()
+	test.NoError(writeDiagnostics(errs, diagnosticsJSON, &stdout, &stderr))
+	test.Contains(stdout.String(), `"code": "E0048"`)
+	test.Contains(stdout.String(), `"message": "called from program"`)
+}
+
+func TestUnexpectedArguments(t *testing.T) {
+	test := require.New(t)
+	inProject(t)
+
+	stdout, stderr := strings.Builder{}, strings.Builder{}
+	test.Equal(2, run([]string{"build", "extra"}, &stdout, &stderr))
+	test.Contains(stderr.String(), "unexpected arguments for `build`: [extra]")
+	test.Empty(stdout.String())
+
+	stderr.Reset()
+	test.Equal(2, run([]string{"test", "--diagnostics=xml"}, &stdout, &stderr))
+	test.Contains(stderr.String(), "invalid diagnostics format `xml`")
+}
+
+// Creates a project in a temporary directory and makes it the working
+// directory for the test.
+func inProject(t *testing.T) {
+	dir := t.TempDir()
+	_, err := project.Init(dir, "app")
+	require.NoError(t, err)
+
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() {
+		os.Chdir(cwd)
+	})
+}
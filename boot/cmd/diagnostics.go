@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"axlab.dev/bit/base"
+)
+
+type diagnosticsFormat string
+
+const (
+	diagnosticsText  diagnosticsFormat = "text"
+	diagnosticsJSON  diagnosticsFormat = "json"
+	diagnosticsSARIF diagnosticsFormat = "sarif"
+)
+
+func parseDiagnosticsFormat(name string) (diagnosticsFormat, error) {
+	switch format := diagnosticsFormat(name); format {
+	case diagnosticsText, diagnosticsJSON, diagnosticsSARIF:
+		return format, nil
+	}
+	return "", fmt.Errorf("invalid diagnostics format `%s`, expected text, json or sarif", name)
+}
+
+// Writes the diagnostics sorted by location. Text goes to the standard
+// error, while the machine-readable formats always write a document to the
+// standard output, even if empty.
+func writeDiagnostics(errs *base.ErrorSet, format diagnosticsFormat, stdout, stderr io.Writer) error {
+	errs.Sort()
+	switch format {
+	case diagnosticsJSON:
+		data, err := json.MarshalIndent(errs, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(stdout, "%s\n", data)
+		return err
+	case diagnosticsSARIF:
+		return errs.WriteSARIF(stdout, "bit", base.Version())
+	default:
+		for _, it := range errs.Diagnostics() {
+			if _, err := fmt.Fprintln(stderr, it.Error()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"axlab.dev/bit/code"
+)
+
+// Prints the explanation for a diagnostic code, returning the exit status.
+func explain(args []string, stdout, stderr io.Writer) int {
+	if len(args) != 1 {
+		fmt.Fprintf(stderr, "usage: bit explain <code>\n")
+		return 2
+	}
+
+	name := strings.ToUpper(args[0])
+	info, ok := code.LookupCode(name)
+	if !ok {
+		fmt.Fprintf(stderr, "error: unknown diagnostic code `%s`\n", args[0])
+		return 1
+	}
+
+	fmt.Fprintf(stdout, "%s: %s\n\n%s\n", info.Code, info.Title, info.Explain)
+	return 0
+}
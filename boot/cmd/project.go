@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"axlab.dev/bit/base"
+	"axlab.dev/bit/project"
+)
+
+// Scaffolds a new project, returning the exit status.
+func initProject(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("init", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	name := flags.String("name", "", "project name, defaults to the directory name")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 1 {
+		fmt.Fprintf(stderr, "usage: bit init [--name <name>] [<dir>]\n")
+		return 2
+	}
+
+	dir := "."
+	if flags.NArg() == 1 {
+		dir = flags.Arg(0)
+	}
+
+	created, err := project.Init(dir, *name)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(stdout, "created project `%s` in %s\n", created.Manifest.Name, created.Dir)
+	return 0
+}
+
+// Resolves the dependencies for the project in the working directory into
+// its vendor directory, updating the lockfile.
+func vendorProject(update bool, errs *base.ErrorSet) {
+	current, err := openProject()
+	if err != nil {
+		errs.Add(err)
+		return
+	}
+
+	if _, err := current.Vendor(update); err != nil {
+		errs.Add(err)
+	}
+}
+
+// Loads the project for the working directory, from the nearest manifest
+// up the directory tree, and checks its layout and dependencies.
+//
+// For `bit test`, the project must have at least one test module.
+//
+// Compiling source files needs a parser, which does not exist yet, so once
+// the checks pass the command reports that it is not implemented. In
+// particular, `bit run` cannot report runtime errors yet. When it can, a
+// code.RuntimeError added to the error set is written as a diagnostic at
+// the failing location, with the calling locations as related spans.
+func checkProject(command string, errs *base.ErrorSet) {
+	current, err := openProject()
+	if err != nil {
+		errs.Add(err)
+		return
+	}
+
+	modules, err := current.Check()
+	if err != nil {
+		errs.Add(err)
+		return
+	}
+
+	if command == "test" && len(project.Tests(modules)) == 0 {
+		errs.Add(fmt.Errorf("no test modules found, test module names end with `%s`", project.TestSuffix))
+		return
+	}
+
+	errs.Add(fmt.Errorf("`bit %s` is not implemented yet: the project `%s` is valid, but source files cannot be parsed", command, current.Manifest.Name))
+}
+
+func openProject() (*project.Project, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	return project.Open(dir)
+}
@@ -0,0 +1,38 @@
+package code
+
+// Returns the builtin function with the given name, or nil. Builtin
+// functions are resolved after all the variables in scope.
+//
+// The functions are created on the first lookup and shared by everything
+// using the TypeSet.
+func (set *TypeSet) builtinFunc(name Id) *Native {
+	out := &set.builtin
+	out.funcsInit.Do(func() {
+		out.funcs = make(map[Id]*Native)
+		for _, it := range stdinFuncs(set) {
+			out.funcs[it.Name] = it
+		}
+	})
+	return out.funcs[name]
+}
+
+// Returns the builtin methods for a type.
+func builtinMethods(typ Type) (out []*Native) {
+	switch def := typ.Def().(type) {
+	case TypeScalar:
+		if kind := typ.fixedKind(); kind != nil {
+			out = append(out, fixedMethods(typ, kind)...)
+		}
+		switch def.Kind() {
+		case TypeScalarString:
+			out = append(out, stringMethods(typ)...)
+		case TypeScalarBytes:
+			out = append(out, bytesMethods(typ)...)
+		}
+	case TypeList:
+		out = append(out, listMethods(typ, def)...)
+	case TypeMap:
+		out = append(out, mapMethods(typ, def)...)
+	}
+	return out
+}
@@ -0,0 +1,132 @@
+package code
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"axlab.dev/bit/base"
+)
+
+// Literal for an immutable byte sequence.
+type Bytes struct {
+	Value []byte
+}
+
+func (expr Bytes) IsExpr() {}
+
+func (expr Bytes) String() string {
+	return fmt.Sprintf("Bytes(%s)", BytesValue(expr.Value))
+}
+
+// Parses the hexadecimal digits in a `x"deadbeef"` literal.
+//
+// Digits can be separated by whitespace or `_`.
+func BytesHex(text string) (out Bytes, err error) {
+	digits := strings.Builder{}
+	for n, chr := range text {
+		switch {
+		case chr == '_' || base.IsSpace(chr):
+			continue
+		case '0' <= chr && chr <= '9', 'a' <= chr && chr <= 'f', 'A' <= chr && chr <= 'F':
+			digits.WriteRune(chr)
+		default:
+			return out, errorf(codeInvalidLiteral, "invalid hex digit `%c` at offset %d in bytes literal", chr, n)
+		}
+	}
+
+	if digits.Len()%2 != 0 {
+		return out, errorf(codeInvalidLiteral, "odd number of hex digits in bytes literal")
+	}
+
+	out.Value, err = hex.DecodeString(digits.String())
+	return out, err
+}
+
+// Runtime value for `Bytes`. It uses a string so values are immutable and
+// comparable.
+type BytesValue string
+
+func (val BytesValue) String() string {
+	return fmt.Sprintf(`x"%x"`, string(val))
+}
+
+func compileBytes(scope *Scope, expr Bytes) (eval EvalFunc, typ Type, err error) {
+	value := BytesValue(expr.Value)
+	eval = func(rt *Runtime) (out any, err error) {
+		return value, nil
+	}
+	return eval, scope.Types().Scalar(TypeScalarBytes), nil
+}
+
+func compileBytesIndex(scope *Scope, value EvalFunc, indexExpr Expr) (eval EvalFunc, typ Type, err error) {
+	index, err := compileIndexValue(scope, indexExpr)
+	if err != nil {
+		return nil, typ, err
+	}
+
+	eval = func(rt *Runtime) (out any, err error) {
+		val, err := value(rt)
+		if err != nil {
+			return nil, err
+		}
+
+		idx, err := index(rt)
+		if err != nil {
+			return nil, err
+		}
+
+		bytes := val.(BytesValue)
+		pos, err := checkIndex(idx, len(bytes))
+		if err != nil {
+			return nil, err
+		}
+		return bytes[pos], nil
+	}
+	return eval, scope.Types().Scalar(TypeScalarU8), nil
+}
+
+// Returns the error for invalid UTF-8 text, or nil.
+func checkUTF8(text string) error {
+	for n, chr := range text {
+		if chr == utf8.RuneError {
+			if _, size := utf8.DecodeRuneInString(text[n:]); size <= 1 {
+				return errorf(codeInvalidUTF8, "invalid UTF-8 at byte offset %d", n)
+			}
+		}
+	}
+	return nil
+}
+
+func bytesMethods(typ Type) []*Native {
+	types := typ.Set()
+	typeStr := types.Scalar(TypeScalarString)
+	return []*Native{
+		{
+			Name: "len",
+			Type: types.Func(types.Scalar(TypeScalarInt), typ),
+			Eval: func(rt *Runtime, args []any) (out any, err error) {
+				return int64(len(args[0].(BytesValue))), nil
+			},
+		},
+		{
+			Name: "hex",
+			Type: types.Func(typeStr, typ),
+			Eval: func(rt *Runtime, args []any) (out any, err error) {
+				return hex.EncodeToString([]byte(args[0].(BytesValue))), nil
+			},
+		},
+		{
+			Name: "to_string",
+			Type: types.Func(types.Result(typeStr, types.Error()), typ),
+			Eval: func(rt *Runtime, args []any) (out any, err error) {
+				text := string(args[0].(BytesValue))
+				if err := checkUTF8(text); err != nil {
+					return nil, err
+				}
+				return text, nil
+			},
+		},
+	}
+}
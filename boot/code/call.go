@@ -0,0 +1,174 @@
+package code
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Call struct {
+	Func Expr
+	Args []Expr
+}
+
+func (expr Call) IsExpr() {}
+
+func (expr Call) String() string {
+	out := strings.Builder{}
+	out.WriteString("Call(")
+	out.WriteString(expr.Func.String())
+	for _, it := range expr.Args {
+		out.WriteString(", ")
+		out.WriteString(it.String())
+	}
+	out.WriteString(")")
+	return out.String()
+}
+
+func compileCall(scope *Scope, expr Call) (eval EvalFunc, typ Type, err error) {
+	// lookup errors are reported when compiling the callee below
+	switch v := expr.Func.Value().(type) {
+	case Var:
+		id, decl, err := scope.lookup(v.Name)
+		if err == nil && decl.fn != nil && len(decl.fn.expr.Generics) > 0 {
+			return compileGenericCall(scope, expr, decl.fn, func(rt *Runtime) *FuncValue {
+				return rt.GetVar(id).(*FuncValue)
+			})
+		}
+	case Qualified:
+		decl, err := scope.resolveQualified(v)
+		if err == nil && decl.fn != nil && len(decl.fn.expr.Generics) > 0 {
+			index := decl.index
+			return compileGenericCall(scope, expr, decl.fn, func(rt *Runtime) *FuncValue {
+				return rt.rootFrame().vars[index].(*FuncValue)
+			})
+		}
+	}
+
+	callee, calleeType, err := compileExpr(scope, expr.Func)
+	if err != nil {
+		return nil, typ, err
+	}
+
+	fn, ok := calleeType.Def().(TypeFunc)
+	if !ok {
+		return nil, typ, errorf(codeNotCallable, "cannot call value of type `%s`", calleeType)
+	}
+
+	args, err := compileArgs(scope, expr.Args, fn.params, nil)
+	if err != nil {
+		return nil, typ, err
+	}
+
+	eval = func(rt *Runtime) (out any, err error) {
+		val, err := callee(rt)
+		if err != nil {
+			return nil, err
+		}
+
+		argValues, err := evalArgs(rt, args)
+		if err != nil {
+			return nil, err
+		}
+
+		return rt.callValue(val, argValues)
+	}
+	return eval, fn.result, nil
+}
+
+// Calls to generic functions infer the type arguments from the argument
+// types and then call the instance compiled for those. The function value
+// is only used for its environment.
+func compileGenericCall(scope *Scope, expr Call, def *funcDef, value func(rt *Runtime) *FuncValue) (eval EvalFunc, typ Type, err error) {
+	types := scope.Types()
+	unifier := types.newUnifier()
+
+	generics := def.expr.Generics
+	vars := unifier.fresh(generics)
+	fn := types.Substitute(def.typ, generics, vars).Def().(TypeFunc)
+
+	args, err := compileArgs(scope, expr.Args, fn.params, unifier)
+	if err != nil {
+		return nil, typ, fmt.Errorf("in call to `%s`: %w", def.expr.Name, err)
+	}
+
+	typeArgs, err := resolveTypeArgs(unifier, generics, vars, def.expr.Name)
+	if err != nil {
+		return nil, typ, err
+	}
+
+	code, err := def.instance(typeArgs)
+	if err != nil {
+		return nil, typ, err
+	}
+
+	eval = func(rt *Runtime) (out any, err error) {
+		argValues, err := evalArgs(rt, args)
+		if err != nil {
+			return nil, err
+		}
+
+		return rt.call(code, value(rt).env, argValues)
+	}
+	return eval, unifier.resolve(fn.result), nil
+}
+
+// Resolves the inferred type arguments for a generic function, checking
+// that they are fully known and satisfy the type parameter bounds.
+func resolveTypeArgs(unifier *typeUnifier, generics, vars []Type, name Id) (args []Type, err error) {
+	args = make([]Type, len(vars))
+	for n, it := range vars {
+		args[n] = unifier.resolve(it)
+		if args[n].HasVars() {
+			return nil, errorf(codeCannotInfer, "cannot infer type argument `%s` for `%s`", generics[n], name)
+		}
+		for _, bound := range generics[n].Def().(TypeVar).bounds {
+			if !unifier.set.Implements(args[n], bound) {
+				return nil, errorf(codeNotImplemented, "type `%s` does not implement `%s` for `%s` in `%s`", args[n], bound, generics[n], name)
+			}
+		}
+	}
+	return args, nil
+}
+
+// Compiles the arguments for a call, checking them against the parameter
+// types. If an unifier is given, the parameter types are unified with the
+// argument types first.
+func compileArgs(scope *Scope, list []Expr, params []Type, unifier *typeUnifier) (args []EvalFunc, err error) {
+	if len(list) != len(params) {
+		return nil, errorf(codeArgCount, "expected %d arguments, got %d", len(params), len(list))
+	}
+
+	args = make([]EvalFunc, len(list))
+	types := make([]Type, len(list))
+	for n, it := range list {
+		if args[n], types[n], err = compileExpr(scope, it); err != nil {
+			return nil, err
+		}
+		if unifier != nil && !isTraitObject(unifier.resolve(params[n])) {
+			if err := unifier.unify(params[n], types[n]); err != nil {
+				return nil, fmt.Errorf("argument %d: %w", n+1, err)
+			}
+		}
+	}
+
+	for n, it := range params {
+		if unifier != nil {
+			it = unifier.resolve(it)
+		}
+		if args[n], err = coerce(scope, args[n], types[n], it); err != nil {
+			return nil, fmt.Errorf("argument %d: %w", n+1, err)
+		}
+	}
+
+	return args, nil
+}
+
+func evalArgs(rt *Runtime, args []EvalFunc) (out []any, err error) {
+	out = make([]any, len(args))
+	for n, it := range args {
+		if out[n], err = it(rt); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
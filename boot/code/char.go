@@ -0,0 +1,45 @@
+package code
+
+import (
+	"fmt"
+	"math/big"
+	"unicode/utf8"
+)
+
+// Literal for a `Char`, which is a Unicode code point.
+type Char struct {
+	Value rune
+}
+
+func (expr Char) IsExpr() {}
+
+func (expr Char) String() string {
+	return fmt.Sprintf("Char(%q)", expr.Value)
+}
+
+// Runtime value for `Char`.
+type CharValue rune
+
+func (val CharValue) String() string {
+	return string(rune(val))
+}
+
+func compileChar(scope *Scope, expr Char) (eval EvalFunc, typ Type, err error) {
+	if !utf8.ValidRune(expr.Value) {
+		return nil, typ, errorf(codeInvalidLiteral, "invalid Unicode code point `%U` in char literal", expr.Value)
+	}
+
+	value := CharValue(expr.Value)
+	eval = func(rt *Runtime) (out any, err error) {
+		return value, nil
+	}
+	return eval, scope.Types().Scalar(TypeScalarChar), nil
+}
+
+// Returns the char for an integer code point.
+func charFromBig(val *big.Int) (CharValue, error) {
+	if !val.IsInt64() || val.Int64() > utf8.MaxRune || !utf8.ValidRune(rune(val.Int64())) {
+		return 0, errorf(codeInvalidCodePoint, "invalid Unicode code point `%s`", val)
+	}
+	return CharValue(val.Int64()), nil
+}
@@ -0,0 +1,128 @@
+package code
+
+import (
+	_ "embed"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"axlab.dev/bit/base"
+)
+
+// Registered code for the compiler diagnostics, with a long-form
+// explanation and examples from the catalog in `codes.md`.
+type CodeInfo struct {
+	Code    string
+	Title   string
+	Explain string
+}
+
+// Codes for compiler diagnostics. Codes are stable and must never be
+// reused for a different kind of diagnostic.
+const (
+	codeRedeclared       = "E0001"
+	codeUndefined        = "E0002"
+	codeTypeMismatch     = "E0003"
+	codeInfiniteType     = "E0004"
+	codeArgCount         = "E0005"
+	codeCannotInfer      = "E0006"
+	codeNotImplemented   = "E0007"
+	codeNotCallable      = "E0008"
+	codeGenericValue     = "E0009"
+	codeInvalidExpr      = "E0010"
+	codeInvalidOperator  = "E0011"
+	codeInvalidConvert   = "E0012"
+	codeUnknownVariant   = "E0013"
+	codeVariantValue     = "E0014"
+	codeNotEnum          = "E0015"
+	codeDuplicateCase    = "E0016"
+	codeMissingCase      = "E0017"
+	codeInvalidLoop      = "E0018"
+	codeImplPlacement    = "E0019"
+	codeInvalidImpl      = "E0020"
+	codeImplMethod       = "E0021"
+	codeConflictingImpl  = "E0022"
+	codeInvalidIndex     = "E0023"
+	codeEmptyLiteral     = "E0024"
+	codeInvalidMapKey    = "E0025"
+	codeUnknownMethod    = "E0026"
+	codeAmbiguousMethod  = "E0027"
+	codeInvalidTrait     = "E0028"
+	codeInvalidRecord    = "E0029"
+	codeInvalidTry       = "E0030"
+	codeInvalidLiteral   = "E0031"
+	codeLiteralRange     = "E0032"
+	codeInvalidFormat    = "E0033"
+	codeCyclicInit       = "E0034"
+	codeTypeArgs         = "E0035"
+	codeTooManyVars      = "E0036"
+	codeImportCycle      = "E0037"
+	codePrivate          = "E0038"
+	codeModuleNotFound   = "E0039"
+	codeInvalidImport    = "E0040"
+	codeInstanceDepth    = "E0041"
+	codeDivByZero        = "E0042"
+	codeShiftRange       = "E0043"
+	codeInvalidTypeDef   = "E0044"
+	codeInvalidUTF8      = "E0045"
+	codeInvalidCodePoint = "E0046"
+	codeIntOverflow      = "E0047"
+	codeOutOfBounds      = "E0048"
+	codeKeyNotFound      = "E0049"
+
+	codeShadowed = "W0001"
+	codeUnused   = "W0002"
+)
+
+// Explanations for the codes, as sections starting with a `# CODE: title`
+// header line.
+//
+//go:embed codes.md
+var codesText string
+
+var codeMap = (func() map[string]*CodeInfo {
+	out := make(map[string]*CodeInfo)
+
+	var last *CodeInfo
+	for _, line := range base.Lines(codesText) {
+		if match := reCodeHeader.FindStringSubmatch(line); match != nil {
+			if out[match[1]] != nil {
+				panic(fmt.Sprintf("duplicated diagnostic code %s", match[1]))
+			}
+			last = &CodeInfo{Code: match[1], Title: match[2]}
+			out[last.Code] = last
+		} else if last != nil {
+			last.Explain += line + "\n"
+		}
+	}
+
+	for _, it := range out {
+		it.Explain = strings.TrimRight(base.Text(it.Explain), "\n")
+	}
+	return out
+})()
+
+var reCodeHeader = regexp.MustCompile(`^# ([EW]\d{4}): (.+)$`)
+
+// Returns the registered information for the diagnostic code.
+func LookupCode(code string) (info CodeInfo, ok bool) {
+	if it := codeMap[code]; it != nil {
+		return *it, true
+	}
+	return info, false
+}
+
+// Returns all registered diagnostic codes, sorted by code.
+func Codes() (out []CodeInfo) {
+	for _, it := range codeMap {
+		out = append(out, *it)
+	}
+	sort.Slice(out, func(a, b int) bool { return out[a].Code < out[b].Code })
+	return out
+}
+
+// Returns an error diagnostic with the registered code.
+func errorf(code string, format string, args ...any) error {
+	return &base.Diagnostic{Code: code, Message: fmt.Sprintf(format, args...)}
+}
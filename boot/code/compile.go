@@ -2,25 +2,72 @@ package code
 
 import (
 	"fmt"
+	"math/big"
+
+	"axlab.dev/bit/base"
 )
 
 type EvalFunc func(rt *Runtime) (out any, err error)
 
+// Compiles all modules in the program. Top-level declarations from every
+// module are collected first, then modules are compiled and run after the
+// modules they import.
 func (program *Program) Compile() (eval EvalFunc, err error) {
 	program.codeSync.Lock()
 	defer program.codeSync.Unlock()
-	eval, err = compileList(&program.scope, program.codeList)
-	return
+	program.scope.program = program
+
+	modules := program.sortedModules()
+
+	for _, it := range modules {
+		if it.decls, err = declareTopLevel(&it.scope, it.codeList); err != nil {
+			return nil, err
+		}
+	}
+
+	order, err := moduleOrder(modules)
+	if err != nil {
+		return nil, err
+	}
+
+	code := make([]EvalFunc, len(order))
+	for n, it := range order {
+		if code[n], _, err = compileList(&it.scope, it.codeList); err != nil {
+			return nil, err
+		}
+	}
+
+	eval = func(rt *Runtime) (out any, err error) {
+		cleanup := rt.enterScope(&program.scope, nil)
+		defer cleanup()
+
+		frame := rt.topFrame()
+		for _, module := range modules {
+			for _, it := range module.decls {
+				it.init(frame)
+			}
+		}
+
+		for _, it := range code {
+			if out, err = it(rt); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	}
+	return eval, nil
 }
 
-func compileList(scope *Scope, list []Expr) (eval EvalFunc, err error) {
+func compileList(scope *Scope, list []Expr) (eval EvalFunc, typ Type, err error) {
 	var code []EvalFunc
+	typ = scope.Types().Unit()
 	for _, it := range list {
-		eval, err := compileExpr(scope, it)
+		eval, itType, err := compileStmt(scope, it)
 		if err != nil {
-			return nil, err
+			return nil, typ, err
 		}
 		code = append(code, eval)
+		typ = itType
 	}
 
 	eval = func(rt *Runtime) (out any, err error) {
@@ -32,17 +79,92 @@ func compileList(scope *Scope, list []Expr) (eval EvalFunc, err error) {
 		return out, nil
 	}
 
-	return eval, nil
+	return eval, typ, nil
 }
 
-func compileExpr(scope *Scope, expr Expr) (eval EvalFunc, err error) {
+// Compiles the initializer for a Let, returning the declaration with its
+// resolved type.
+func compileLetInit(scope *Scope, expr Let) (init EvalFunc, decl Var, err error) {
+	init, initType, err := compileExpr(scope, expr.Init)
+	if err != nil {
+		return nil, decl, err
+	}
+
+	decl = expr.Decl
+	if decl.Type.Valid() {
+		decl.Type = scope.Type(decl.Type)
+		if err := decl.Type.checkMapKeys(); err != nil {
+			return nil, decl, err
+		}
+		if init, err = coerce(scope, init, initType, decl.Type); err != nil {
+			return nil, decl, fmt.Errorf("let `%s`: %w", decl.Name, err)
+		}
+	} else {
+		decl.Type = initType
+	}
+	return init, decl, nil
+}
+
+// Compiles the expression, with errors reported at the expression location
+// as a CompileError.
+//
+// Evaluation errors are wrapped as a RuntimeError only at call and statement
+// boundaries (see compileStmt), so nested expressions within a statement are
+// evaluated without any extra indirection.
+func compileExpr(scope *Scope, expr Expr) (eval EvalFunc, typ Type, err error) {
+	eval, typ, err = compileExprValue(scope, expr)
+	if err != nil {
+		return nil, typ, compileError(expr.Span(), err)
+	}
+
+	switch expr.Value().(type) {
+	case Call, MethodCall, TraitCall:
+		eval = evalAt(eval, expr.Span())
+	}
+	return eval, typ, nil
+}
+
+// Compiles an expression evaluated as a statement, such as the items in a
+// block or a function body. Evaluation errors are reported at the statement
+// location, unless a call within it already has one.
+func compileStmt(scope *Scope, expr Expr) (eval EvalFunc, typ Type, err error) {
+	eval, typ, err = compileExpr(scope, expr)
+	if err != nil {
+		return nil, typ, err
+	}
+
+	switch expr.Value().(type) {
+	case Call, MethodCall, TraitCall:
+	default:
+		eval = evalAt(eval, expr.Span())
+	}
+	return eval, typ, nil
+}
+
+func evalAt(eval EvalFunc, span base.Span) EvalFunc {
+	return func(rt *Runtime) (out any, err error) {
+		if out, err = eval(rt); err != nil {
+			err = rt.wrapError(err, span)
+		}
+		return out, err
+	}
+}
+
+func compileExprValue(scope *Scope, expr Expr) (eval EvalFunc, typ Type, err error) {
+	if !expr.Valid() {
+		return nil, typ, errorf(codeInvalidExpr, "cannot compile invalid expression")
+	}
+
+	types := scope.Types()
 	switch val := expr.Value().(type) {
 
 	case Block:
 		blockScope := scope.NewChild()
-		if evalInner, err := compileList(blockScope, val.List); err != nil {
-			return nil, err
+		if evalInner, innerType, err := compileList(blockScope, val.List); err != nil {
+			return nil, typ, err
 		} else {
+			blockScope.checkUnused()
+			typ = innerType
 			eval = func(rt *Runtime) (out any, err error) {
 				cleanup := rt.InitScope(blockScope)
 				defer cleanup()
@@ -51,30 +173,63 @@ func compileExpr(scope *Scope, expr Expr) (eval EvalFunc, err error) {
 		}
 
 	case Let:
-		init, err := compileExpr(scope, val.Init)
+		if decl := scope.decls[expr]; decl != nil {
+			global := decl.global
+			if err := global.compile(); err != nil {
+				return nil, typ, err
+			}
+			return global.get, global.decl.typ, nil
+		}
+
+		init, decl, err := compileLetInit(scope, val)
 		if err != nil {
-			return nil, err
+			return nil, typ, err
 		}
 
-		id, err := scope.Declare(val.Decl)
+		id, err := scope.declare(decl, expr.Span(), nil)
 		if err != nil {
-			return nil, err
+			return nil, typ, err
 		}
 
+		typ = decl.Type
 		eval = func(rt *Runtime) (out any, err error) {
-			if val, err := init(rt); err == nil {
-				rt.SetVar(id, val)
+			if out, err = init(rt); err == nil {
+				rt.SetVar(id, out)
 			}
-			return val, err
+			return out, err
 		}
 
 	case Var:
 
-		id, err := scope.Resolve(val)
+		id, decl, err := scope.lookup(val.Name)
 		if err != nil {
-			return nil, err
+			if native := types.builtinFunc(val.Name); native != nil {
+				return compileNative(scope, native)
+			}
+			return nil, typ, err
+		}
+
+		if decl.fn != nil && len(decl.fn.expr.Generics) > 0 {
+			return nil, typ, errorf(codeGenericValue, "generic function `%s` can only be called", val.Name)
+		}
+
+		// globals declared later have their type inferred when compiled
+		global := decl.global
+		if global != nil {
+			if err := global.compile(); err != nil {
+				return nil, typ, err
+			}
+		}
+
+		if val.Type.Valid() && scope.Type(val.Type) != decl.typ {
+			return nil, typ, errorf(codeTypeMismatch, "variable `%s` has type `%s`, not `%s`", val.Name, decl.typ, val.Type)
+		}
+
+		if global != nil {
+			return global.get, decl.typ, nil
 		}
 
+		typ = decl.typ
 		eval = func(rt *Runtime) (out any, err error) {
 			out = rt.GetVar(id)
 			return
@@ -82,13 +237,16 @@ func compileExpr(scope *Scope, expr Expr) (eval EvalFunc, err error) {
 
 	case Number:
 
+		typ = types.Scalar(TypeScalarNumber)
+		value := big.NewInt(val.Value)
 		eval = func(rt *Runtime) (out any, err error) {
-			out = val.Value
+			out = value
 			return out, nil
 		}
 
 	case Str:
 
+		typ = types.Scalar(TypeScalarString)
 		eval = func(rt *Runtime) (out any, err error) {
 			out = val.Value
 			return out, nil
@@ -97,14 +255,24 @@ func compileExpr(scope *Scope, expr Expr) (eval EvalFunc, err error) {
 	case Print:
 
 		args := make([]EvalFunc, 0, len(val.Args))
+		argTypes := make([]Type, 0, len(val.Args))
+		formats := make([]formatFunc, 0, len(val.Args))
 		for _, arg := range val.Args {
-			if fn, err := compileExpr(scope, arg); err == nil {
+			if fn, argType, err := compileExpr(scope, arg); err == nil {
 				args = append(args, fn)
+				argTypes = append(argTypes, argType)
 			} else {
-				return nil, err
+				return nil, typ, err
+			}
+
+			if format, err := types.formatter(argTypes[len(argTypes)-1]); err == nil {
+				formats = append(formats, format)
+			} else {
+				return nil, typ, err
 			}
 		}
 
+		typ = types.Tuple(argTypes...)
 		eval = func(rt *Runtime) (out any, err error) {
 			if len(args) > 0 {
 				vals := make([]any, len(args))
@@ -118,8 +286,12 @@ func compileExpr(scope *Scope, expr Expr) (eval EvalFunc, err error) {
 				out = vals
 
 				sep := ""
-				for _, it := range vals {
-					if _, err := fmt.Fprintf(rt.StdOut, "%s%v", sep, it); err != nil {
+				for n, it := range vals {
+					text, err := formats[n](rt, it)
+					if err != nil {
+						return nil, err
+					}
+					if _, err := fmt.Fprintf(rt.StdOut, "%s%s", sep, text); err != nil {
 						return nil, fmt.Errorf("print: %w", err)
 					}
 					sep = " "
@@ -133,9 +305,111 @@ func compileExpr(scope *Scope, expr Expr) (eval EvalFunc, err error) {
 			return out, nil
 		}
 
+	case Func:
+		if decl := scope.decls[expr]; decl != nil {
+			return compileTopFunc(decl)
+		}
+		return compileFunc(scope, val, expr.Span())
+
+	case Call:
+		return compileCall(scope, val)
+
+	case Record:
+		return compileRecord(scope, val)
+
+	case Field:
+		return compileField(scope, val)
+
+	case Variant:
+		return compileVariant(scope, val)
+
+	case Match:
+		return compileMatch(scope, val, expr.Span())
+
+	case Impl:
+		return compileImpl(scope, expr)
+
+	case Import:
+		return compileImport(scope, expr)
+
+	case Qualified:
+		return compileQualified(scope, val)
+
+	case TraitCall:
+		return compileTraitCall(scope, val)
+
+	case MethodCall:
+		return compileMethodCall(scope, val)
+
+	case List:
+		return compileListLiteral(scope, val)
+
+	case Index:
+		return compileIndex(scope, val)
+
+	case Slice:
+		return compileSlice(scope, val)
+
+	case Tuple:
+		return compileTuple(scope, val)
+
+	case Map:
+		return compileMapLiteral(scope, val)
+
+	case For:
+		return compileFor(scope, val, expr.Span())
+
+	case Format:
+		return compileFormat(scope, val)
+
+	case Interp:
+		return compileInterp(scope, val)
+
+	case Integer:
+		return compileInteger(scope, val)
+
+	case Float:
+		return compileFloat(scope, val)
+
+	case Bytes:
+		return compileBytes(scope, val)
+
+	case Char:
+		return compileChar(scope, val)
+
+	case Binary:
+		return compileBinary(scope, val)
+
+	case Unary:
+		return compileUnary(scope, val)
+
+	case Convert:
+		return compileConvert(scope, val)
+
+	case Try:
+		return compileTry(scope, val)
+
+	case *Native:
+		return compileNative(scope, val)
+
 	default:
-		return nil, fmt.Errorf("cannot compile expression: %s", expr)
+		return nil, typ, errorf(codeInvalidExpr, "cannot compile expression: %s", expr)
 	}
 
-	return eval, err
+	return eval, typ, err
+}
+
+// Converts a value of the given type to the expected type, failing if
+// the types are not compatible.
+func coerce(scope *Scope, eval EvalFunc, from, to Type) (EvalFunc, error) {
+	if from == to {
+		return eval, nil
+	}
+	if types := scope.Types(); from == types.Scalar(TypeScalarInt) && to == types.Scalar(TypeScalarNumber) {
+		return promoteInt(eval), nil
+	}
+	if _, ok := to.Def().(TypeTrait); ok {
+		return coerceTrait(scope, eval, from, to)
+	}
+	return nil, errorf(codeTypeMismatch, "type mismatch: expected `%s`, got `%s`", to, from)
 }
@@ -0,0 +1,90 @@
+package code
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Explicit conversion of a value to a type, i.e. `value as Type`.
+//
+// Integers can be converted to any other integer type, failing at runtime
+// if the value does not fit in the target type.
+//
+// A `String` converts to its UTF-8 `Bytes`, and `Bytes` to a `String`
+// failing if they are not valid UTF-8. A `Char` converts to a `String` and
+// to or from its integer code point.
+type Convert struct {
+	Value Expr
+	Type  Type
+}
+
+func (expr Convert) IsExpr() {}
+
+func (expr Convert) String() string {
+	return fmt.Sprintf("Convert(%s as %s)", expr.Value, expr.Type)
+}
+
+func compileConvert(scope *Scope, expr Convert) (eval EvalFunc, typ Type, err error) {
+	value, valueType, err := compileExpr(scope, expr.Value)
+	if err != nil {
+		return nil, typ, err
+	}
+
+	types := scope.Types()
+	typeStr := types.Scalar(TypeScalarString)
+	typeBytes := types.Scalar(TypeScalarBytes)
+	typeChar := types.Scalar(TypeScalarChar)
+
+	typ = scope.Type(expr.Type)
+	switch {
+	case valueType == typ:
+		return value, typ, nil
+	case valueType == types.Scalar(TypeScalarInt) && typ == types.Scalar(TypeScalarNumber):
+		return promoteInt(value), typ, nil
+	case valueType.isInteger() && typ.isInteger():
+		return convertWith(value, func(val any) (any, error) {
+			return integerFromBig(typ, integerToBig(valueType, val))
+		}), typ, nil
+
+	case valueType == typeStr && typ == typeBytes:
+		return convertWith(value, func(val any) (any, error) {
+			return BytesValue(val.(string)), nil
+		}), typ, nil
+
+	case valueType == typeBytes && typ == typeStr:
+		return convertWith(value, func(val any) (any, error) {
+			text := string(val.(BytesValue))
+			if err := checkUTF8(text); err != nil {
+				return nil, err
+			}
+			return text, nil
+		}), typ, nil
+
+	case valueType == typeChar && typ == typeStr:
+		return convertWith(value, func(val any) (any, error) {
+			return string(rune(val.(CharValue))), nil
+		}), typ, nil
+
+	case valueType == typeChar && typ.isInteger():
+		return convertWith(value, func(val any) (any, error) {
+			return integerFromBig(typ, big.NewInt(int64(val.(CharValue))))
+		}), typ, nil
+
+	case valueType.isInteger() && typ == typeChar:
+		return convertWith(value, func(val any) (any, error) {
+			return charFromBig(integerToBig(valueType, val))
+		}), typ, nil
+	}
+
+	return nil, typ, errorf(codeInvalidConvert, "cannot convert `%s` to `%s`", valueType, typ)
+}
+
+func convertWith(value EvalFunc, conv func(val any) (any, error)) EvalFunc {
+	return func(rt *Runtime) (out any, err error) {
+		val, err := value(rt)
+		if err != nil {
+			return nil, err
+		}
+		return conv(val)
+	}
+}
@@ -0,0 +1,65 @@
+package code
+
+// Declaration collected from the top level of the program before compiling
+// any code, so it is visible regardless of the declaration order.
+//
+// This allows functions to be mutually recursive and methods and trait
+// impls to be used before their Impl.
+type topDecl struct {
+	id VarId
+
+	// declared value, one of these is set for each declaration
+	global *globalDef
+	fn     *funcDef
+	impl   []*funcDef
+
+	// compiled code for a function, set when compiling its body
+	code *funcCode
+}
+
+// Collects the top-level declarations in a module scope.
+func declareTopLevel(scope *Scope, list []Expr) (decls []*topDecl, err error) {
+	scope.decls = make(map[Expr]*topDecl)
+	for _, it := range list {
+		decl := &topDecl{}
+		switch val := it.Value().(type) {
+		case Let:
+			decl.global = &globalDef{expr: val, scope: scope}
+			decl.id, err = scope.declare(val.Decl, it.Span(), nil)
+			if err == nil {
+				decl.global.decl, _ = scope.tryResolve(val.Decl.Name)
+				decl.global.decl.global = decl.global
+				decl.global.decl.pub = val.Pub
+			}
+		case Func:
+			decl.fn = newFuncDef(scope, val, it.Span())
+			decl.id, err = scope.declare(Var{Name: val.Name, Type: decl.fn.typ}, it.Span(), decl.fn)
+			if err == nil {
+				fn, _ := scope.tryResolve(val.Name)
+				fn.pub = val.Pub
+			}
+		case Impl:
+			decl.impl, err = declareImpl(scope, val, it.Span())
+		case Import:
+			err = scope.module.declareImport(val, it.Span())
+		default:
+			continue
+		}
+
+		if err != nil {
+			return nil, compileError(it.Span(), err)
+		}
+		scope.decls[it] = decl
+		decls = append(decls, decl)
+	}
+	return decls, nil
+}
+
+// Sets the initial values for the declarations in the program frame.
+func (decl *topDecl) init(frame *stackFrame) {
+	if decl.global != nil {
+		frame.vars[decl.id.index] = globalPending
+	} else if decl.fn != nil {
+		frame.vars[decl.id.index] = &FuncValue{code: decl.code, env: frame}
+	}
+}
@@ -0,0 +1,222 @@
+package code
+
+import (
+	"fmt"
+	"strings"
+
+	"axlab.dev/bit/base"
+)
+
+// Creates an enum value for the named variant.
+//
+// The payload Value must be given only for variants that have one. For a
+// generic enum, the type arguments are inferred from the payload, so a
+// variant without payload requires an instantiated Type.
+type Variant struct {
+	Type  Type
+	Name  Id
+	Value Expr
+}
+
+func (expr Variant) IsExpr() {}
+
+func (expr Variant) String() string {
+	if expr.Value.Valid() {
+		return fmt.Sprintf("Variant(%s.%s, %s)", expr.Type, expr.Name, expr.Value)
+	}
+	return fmt.Sprintf("Variant(%s.%s)", expr.Type, expr.Name)
+}
+
+// Selects a case based on the variant of an enum value.
+//
+// A case with an empty Variant matches any variant not listed. Cases can
+// bind the variant payload to a variable.
+type Match struct {
+	Value Expr
+	Cases []MatchCase
+}
+
+type MatchCase struct {
+	Variant Id
+	Bind    Var
+	Body    Expr
+}
+
+func (expr Match) IsExpr() {}
+
+func (expr Match) String() string {
+	out := strings.Builder{}
+	out.WriteString("Match(")
+	out.WriteString(expr.Value.String())
+	for _, it := range expr.Cases {
+		out.WriteString("; ")
+		if it.Variant == "" {
+			out.WriteString("_")
+		} else {
+			out.WriteString(string(it.Variant))
+		}
+		if it.Bind.Name != "" {
+			out.WriteString(fmt.Sprintf("(%s)", it.Bind.Name))
+		}
+		out.WriteString(" => ")
+		out.WriteString(it.Body.String())
+	}
+	out.WriteString(")")
+	return out.String()
+}
+
+// Runtime value for an enum.
+type EnumValue struct {
+	Variant int
+	Value   any
+}
+
+func compileVariant(scope *Scope, expr Variant) (eval EvalFunc, typ Type, err error) {
+	unifier := scope.Types().newUnifier()
+
+	typ = unifier.instantiate(scope.Type(expr.Type))
+	enum, ok := typ.Def().(TypeEnum)
+	if !ok {
+		return nil, typ, errorf(codeNotEnum, "`%s` is not an enum type", typ)
+	}
+
+	index := enum.VariantIndex(expr.Name)
+	if index < 0 {
+		return nil, typ, errorf(codeUnknownVariant, "enum `%s` has no variant `%s`", enum.Name(), expr.Name)
+	}
+
+	variant := enum.Variants()[index]
+	if variant.Type.Valid() != expr.Value.Valid() {
+		if variant.Type.Valid() {
+			return nil, typ, errorf(codeVariantValue, "variant `%s.%s` requires a value", enum.Name(), expr.Name)
+		}
+		return nil, typ, errorf(codeVariantValue, "variant `%s.%s` does not have a value", enum.Name(), expr.Name)
+	}
+
+	var value EvalFunc
+	if expr.Value.Valid() {
+		args, err := compileArgs(scope, []Expr{expr.Value}, []Type{variant.Type}, unifier)
+		if err != nil {
+			return nil, typ, fmt.Errorf("variant `%s.%s`: %w", enum.Name(), expr.Name, err)
+		}
+		value = args[0]
+	}
+
+	if typ = unifier.resolve(typ); typ.HasVars() {
+		return nil, typ, errorf(codeCannotInfer, "cannot infer type arguments for enum `%s`", enum.Name())
+	}
+
+	eval = func(rt *Runtime) (out any, err error) {
+		out = EnumValue{Variant: index}
+		if value != nil {
+			val, err := value(rt)
+			if err != nil {
+				return nil, err
+			}
+			out = EnumValue{Variant: index, Value: val}
+		}
+		return out, nil
+	}
+	return eval, typ, nil
+}
+
+func compileMatch(scope *Scope, expr Match, span base.Span) (eval EvalFunc, typ Type, err error) {
+	value, valueType, err := compileExpr(scope, expr.Value)
+	if err != nil {
+		return nil, typ, err
+	}
+
+	enum, ok := valueType.Def().(TypeEnum)
+	if !ok {
+		return nil, typ, errorf(codeNotEnum, "cannot match on non-enum type `%s`", valueType)
+	}
+
+	type matchCase struct {
+		scope *Scope
+		bind  bool
+		body  EvalFunc
+	}
+
+	variants := enum.Variants()
+	cases := make([]*matchCase, len(variants))
+	var other *matchCase
+
+	for _, it := range expr.Cases {
+		index := -1
+		if it.Variant != "" {
+			if index = enum.VariantIndex(it.Variant); index < 0 {
+				return nil, typ, errorf(codeUnknownVariant, "enum `%s` has no variant `%s`", enum.Name(), it.Variant)
+			} else if cases[index] != nil {
+				return nil, typ, errorf(codeDuplicateCase, "duplicated match case for `%s`", it.Variant)
+			}
+		} else if other != nil {
+			return nil, typ, errorf(codeDuplicateCase, "duplicated default match case")
+		}
+
+		current := &matchCase{scope: scope.NewChild()}
+		if it.Bind.Name != "" {
+			if index < 0 || !variants[index].Type.Valid() {
+				return nil, typ, errorf(codeVariantValue, "match case `%s` has no value to bind", it.Variant)
+			}
+
+			bind := Var{Name: it.Bind.Name, Type: variants[index].Type}
+			if it.Bind.Type.Valid() && current.scope.Type(it.Bind.Type) != bind.Type {
+				return nil, typ, errorf(codeTypeMismatch, "match case `%s` binds `%s`, got `%s`", it.Variant, bind.Type, it.Bind.Type)
+			}
+			if _, err := current.scope.declare(bind, it.Bind.declSpan(span), nil); err != nil {
+				return nil, typ, err
+			}
+			current.bind = true
+		}
+
+		body, bodyType, err := compileStmt(current.scope, it.Body)
+		if err != nil {
+			return nil, typ, err
+		}
+		current.scope.checkUnused()
+
+		if !typ.Valid() {
+			typ = bodyType
+		}
+		if current.body, err = coerce(scope, body, bodyType, typ); err != nil {
+			return nil, typ, fmt.Errorf("match case `%s`: %w", it.Variant, err)
+		}
+
+		if index < 0 {
+			other = current
+		} else {
+			cases[index] = current
+		}
+	}
+
+	for n, it := range cases {
+		if it == nil {
+			if other == nil {
+				return nil, typ, errorf(codeMissingCase, "match on `%s` is missing case `%s`", enum.Name(), variants[n].Name)
+			}
+			cases[n] = other
+		}
+	}
+
+	if !typ.Valid() {
+		typ = scope.Types().Unit()
+	}
+
+	eval = func(rt *Runtime) (out any, err error) {
+		val, err := value(rt)
+		if err != nil {
+			return nil, err
+		}
+
+		enum := val.(EnumValue)
+		current := cases[enum.Variant]
+
+		cleanup := rt.InitScope(current.scope)
+		defer cleanup()
+		if current.bind {
+			rt.topFrame().vars[0] = enum.Value
+		}
+		return current.body(rt)
+	}
+	return eval, typ, nil
+}
@@ -0,0 +1,172 @@
+package code
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"axlab.dev/bit/base"
+)
+
+// Error from compiling the expression at the given location.
+type CompileError struct {
+	Span base.Span
+	Err  error
+}
+
+func (err *CompileError) Error() string {
+	if !err.Span.Valid() {
+		return err.Err.Error()
+	}
+	return fmt.Sprintf("%s: %s", err.Span, err.Err)
+}
+
+func (err *CompileError) Unwrap() error {
+	return err.Err
+}
+
+// Returns the error as a diagnostic at its location.
+//
+// The code and related spans come from the innermost diagnostic, with the
+// message keeping any context added by wrapping it (e.g. `argument 1: `).
+func (err *CompileError) Diagnostic() *base.Diagnostic {
+	var inner base.DiagnosticError
+	if !errors.As(err.Err, &inner) {
+		// internal errors, which are the only ones created without a code
+		diag := base.AsDiagnostic(err.Err)
+		diag.Span = err.Span
+		return diag
+	}
+
+	diag := *inner.Diagnostic()
+	if context, ok := strings.CutSuffix(err.Err.Error(), inner.Error()); ok && !diag.Span.Valid() {
+		diag.Message = context + diag.Message
+	}
+	if !diag.Span.Valid() {
+		diag.Span = err.Span
+	}
+	return &diag
+}
+
+// Wraps the error with the location, unless it already has one.
+func compileError(span base.Span, err error) error {
+	var spanErr *CompileError
+	if !span.Valid() || errors.As(err, &spanErr) {
+		return err
+	}
+	return &CompileError{Span: span, Err: err}
+}
+
+// Returns the related location with the message, if the span is valid.
+func relatedAt(span base.Span, msg string) []base.Related {
+	if !span.Valid() {
+		return nil
+	}
+	return []base.Related{{Span: span, Message: msg}}
+}
+
+// Error from evaluating a program, with the call stack at the point of
+// failure.
+type RuntimeError struct {
+	Err error
+
+	// Active function calls, from the innermost to the program top level.
+	Stack []StackEntry
+}
+
+// Function in the call stack for a RuntimeError, with the location being
+// evaluated in that function when the error occurred.
+//
+// The top-level program has an empty function name.
+type StackEntry struct {
+	Func Id
+	Span base.Span
+}
+
+func (err *RuntimeError) Error() string {
+	return err.Err.Error()
+}
+
+func (err *RuntimeError) Unwrap() error {
+	return err.Err
+}
+
+// Location where the error occurred.
+func (err *RuntimeError) Span() (out base.Span) {
+	if len(err.Stack) > 0 {
+		out = err.Stack[0].Span
+	}
+	return out
+}
+
+// Returns the error as a diagnostic at the location where it occurred, with
+// the calling locations from the stack as related spans.
+func (err *RuntimeError) Diagnostic() *base.Diagnostic {
+	diag := *base.AsDiagnostic(err.Err)
+	if !diag.Span.Valid() {
+		diag.Span = err.Span()
+	}
+	for n := 1; n < len(err.Stack); n++ {
+		if entry := err.Stack[n]; entry.Span.Valid() {
+			msg := fmt.Sprintf("called from %s", entry.name())
+			diag.Related = append(diag.Related, base.Related{Span: entry.Span, Message: msg})
+		}
+	}
+	return &diag
+}
+
+// Renders the error message with the call stack.
+func (err *RuntimeError) Trace() string {
+	out := strings.Builder{}
+	out.WriteString("error: ")
+	out.WriteString(err.Error())
+	for _, it := range err.Stack {
+		out.WriteString("\n    at ")
+		out.WriteString(it.String())
+	}
+	return out.String()
+}
+
+func (entry StackEntry) String() string {
+	if !entry.Span.Valid() {
+		return entry.name()
+	}
+	return fmt.Sprintf("%s (%s)", entry.name(), entry.Span)
+}
+
+func (entry StackEntry) name() string {
+	if entry.Func == "" {
+		return "program"
+	}
+	return fmt.Sprintf("`%s`", entry.Func)
+}
+
+// Wraps an error from evaluating the expression at span as a RuntimeError.
+//
+// Errors from inner expressions are wrapped first. While the error unwinds
+// the stack, this fills the location for each calling function from the
+// call expression.
+func (rt *Runtime) wrapError(err error, span base.Span) error {
+	if _, ok := err.(*funcReturn); ok {
+		return err
+	}
+
+	runtimeErr, ok := err.(*RuntimeError)
+	if !ok {
+		runtimeErr = &RuntimeError{Err: err}
+		for n := len(rt.frames) - 1; n >= 0; n-- {
+			if fn := rt.frames[n].fn; fn != nil {
+				runtimeErr.Stack = append(runtimeErr.Stack, StackEntry{Func: fn.name})
+			}
+		}
+		runtimeErr.Stack = append(runtimeErr.Stack, StackEntry{})
+	}
+
+	if index := len(runtimeErr.Stack) - 1 - rt.callDepth(); index >= 0 && span.Valid() {
+		if entry := &runtimeErr.Stack[index]; !entry.Span.Valid() {
+			entry.Span = span
+		}
+	}
+
+	return runtimeErr
+}
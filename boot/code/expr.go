@@ -1,5 +1,7 @@
 package code
 
+import "axlab.dev/bit/base"
+
 type ExprValue interface {
 	IsExpr()
 	String() string
@@ -9,6 +11,7 @@ type Expr struct{ *exprData }
 
 type exprData struct {
 	value ExprValue
+	span  base.Span
 }
 
 func ExprNew(value ExprValue) Expr {
@@ -16,10 +19,27 @@ func ExprNew(value ExprValue) Expr {
 	return Expr{data}
 }
 
+// Creates an expression for the given source location.
+func ExprAt(span base.Span, value ExprValue) Expr {
+	data := &exprData{value: value, span: span}
+	return Expr{data}
+}
+
+func (expr Expr) Valid() bool {
+	return expr.exprData != nil && expr.value != nil
+}
+
 func (expr Expr) Value() ExprValue {
 	return expr.value
 }
 
+func (expr Expr) Span() (out base.Span) {
+	if expr.exprData != nil {
+		out = expr.span
+	}
+	return out
+}
+
 func (expr Expr) String() string {
 	if expr.exprData == nil {
 		return "Expr(nil)"
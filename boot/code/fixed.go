@@ -0,0 +1,259 @@
+package code
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+)
+
+// Describes an integer type with a fixed width, i.e. `Int` and the sized
+// kinds `i8` to `u64`.
+//
+// At runtime, values use the Go type of the same width and signedness,
+// with `Int` and `i64` both as `int64`. Operations work on the two's
+// complement bit pattern extended to 64 bits and truncate the result.
+type fixedKind struct {
+	bits   uint
+	signed bool
+	wrap   func(v uint64) any
+}
+
+var fixedKinds = map[TypeScalarKind]*fixedKind{
+	TypeScalarInt: {bits: 64, signed: true, wrap: func(v uint64) any { return int64(v) }},
+	TypeScalarI8:  {bits: 8, signed: true, wrap: func(v uint64) any { return int8(v) }},
+	TypeScalarI16: {bits: 16, signed: true, wrap: func(v uint64) any { return int16(v) }},
+	TypeScalarI32: {bits: 32, signed: true, wrap: func(v uint64) any { return int32(v) }},
+	TypeScalarI64: {bits: 64, signed: true, wrap: func(v uint64) any { return int64(v) }},
+	TypeScalarU8:  {bits: 8, wrap: func(v uint64) any { return uint8(v) }},
+	TypeScalarU16: {bits: 16, wrap: func(v uint64) any { return uint16(v) }},
+	TypeScalarU32: {bits: 32, wrap: func(v uint64) any { return uint32(v) }},
+	TypeScalarU64: {bits: 64, wrap: func(v uint64) any { return v }},
+}
+
+// Returns the fixed-width description for the type, or nil.
+func (typ Type) fixedKind() *fixedKind {
+	if scalar, ok := typ.Def().(TypeScalar); ok {
+		return fixedKinds[scalar.kind]
+	}
+	return nil
+}
+
+// Returns the bit pattern for a fixed-width value, sign-extended for
+// signed types.
+func fixedBits(val any) uint64 {
+	switch v := val.(type) {
+	case int8:
+		return uint64(v)
+	case int16:
+		return uint64(v)
+	case int32:
+		return uint64(v)
+	case int64:
+		return uint64(v)
+	case uint8:
+		return uint64(v)
+	case uint16:
+		return uint64(v)
+	case uint32:
+		return uint64(v)
+	case uint64:
+		return v
+	default:
+		panic(fmt.Sprintf("invalid fixed-width value %#v", val))
+	}
+}
+
+func (kind *fixedKind) min() int64 {
+	if !kind.signed {
+		return 0
+	}
+	return -1 << (kind.bits - 1)
+}
+
+func (kind *fixedKind) max() uint64 {
+	if kind.signed {
+		return 1<<(kind.bits-1) - 1
+	}
+	return math.MaxUint64 >> (64 - kind.bits)
+}
+
+// Returns the value for an integer, or false if it is out of range.
+func (kind *fixedKind) fromBig(val *big.Int) (any, bool) {
+	if kind.signed {
+		if !val.IsInt64() || val.Int64() < kind.min() || val.Int64() > int64(kind.max()) {
+			return nil, false
+		}
+		return kind.wrap(uint64(val.Int64())), true
+	}
+	if !val.IsUint64() || val.Uint64() > kind.max() {
+		return nil, false
+	}
+	return kind.wrap(val.Uint64()), true
+}
+
+func (kind *fixedKind) toBig(val any) *big.Int {
+	if kind.signed {
+		return big.NewInt(int64(fixedBits(val)))
+	}
+	return new(big.Int).SetUint64(fixedBits(val))
+}
+
+// Returns the result of a checked arithmetic operation, failing if the
+// result does not fit in the type.
+func (kind *fixedKind) checked(op BinaryOp, typ Type, a, b any) (any, error) {
+	x, y := fixedBits(a), fixedBits(b)
+	if (op == OpDiv || op == OpRem) && y == 0 {
+		return nil, errDivByZero
+	}
+
+	overflow := func() (any, error) {
+		return nil, errIntOverflow(fmt.Sprintf("%v %s %v", a, op, b), typ)
+	}
+
+	if kind.signed {
+		if kind.bits == 64 {
+			out, ok := intOps[op](int64(x), int64(y))
+			if !ok {
+				return overflow()
+			}
+			return kind.wrap(uint64(out)), nil
+		}
+
+		// smaller widths cannot overflow 64 bits
+		var out int64
+		switch op {
+		case OpAdd:
+			out = int64(x) + int64(y)
+		case OpSub:
+			out = int64(x) - int64(y)
+		case OpMul:
+			out = int64(x) * int64(y)
+		case OpDiv:
+			out = int64(x) / int64(y)
+		case OpRem:
+			out = int64(x) % int64(y)
+		}
+		if out < kind.min() || out > int64(kind.max()) {
+			return overflow()
+		}
+		return kind.wrap(uint64(out)), nil
+	}
+
+	var out, carry uint64
+	switch op {
+	case OpAdd:
+		out, carry = bits.Add64(x, y, 0)
+	case OpSub:
+		out, carry = bits.Sub64(x, y, 0)
+	case OpMul:
+		carry, out = bits.Mul64(x, y)
+	case OpDiv:
+		out = x / y
+	case OpRem:
+		out = x % y
+	}
+	if carry != 0 || out > kind.max() {
+		return overflow()
+	}
+	return kind.wrap(out), nil
+}
+
+// Returns the result of a wrapping or bitwise operation.
+func (kind *fixedKind) wrapping(op BinaryOp, a, b any) any {
+	x, y := fixedBits(a), fixedBits(b)
+	switch op {
+	case OpWrapAdd:
+		return kind.wrap(x + y)
+	case OpWrapSub:
+		return kind.wrap(x - y)
+	case OpWrapMul:
+		return kind.wrap(x * y)
+	case OpAnd:
+		return kind.wrap(x & y)
+	case OpOr:
+		return kind.wrap(x | y)
+	case OpXor:
+		return kind.wrap(x ^ y)
+	default:
+		panic(fmt.Sprintf("invalid wrapping operator `%s`", op))
+	}
+}
+
+// Shifts the value, failing if the count is not less than the bit width.
+// Right shifts are arithmetic for signed types.
+func (kind *fixedKind) shift(op BinaryOp, typ Type, val any, count *big.Int) (any, error) {
+	if count.Sign() < 0 || !count.IsUint64() || count.Uint64() >= uint64(kind.bits) {
+		return nil, errorf(codeShiftRange, "shift count `%s` out of range for `%s`", count, typ)
+	}
+
+	x, n := fixedBits(val), count.Uint64()
+	if op == OpShl {
+		return kind.wrap(x << n), nil
+	} else if kind.signed {
+		return kind.wrap(uint64(int64(x) >> n)), nil
+	} else {
+		return kind.wrap(x >> n), nil
+	}
+}
+
+func (kind *fixedKind) rotate(val any, count int64) any {
+	x, n := fixedBits(val), int(count%int64(kind.bits))
+	if n < 0 {
+		n += int(kind.bits)
+	}
+	x &= math.MaxUint64 >> (64 - kind.bits)
+	return kind.wrap(x<<n | x>>(int(kind.bits)-n))
+}
+
+func (kind *fixedKind) popcount(val any) int64 {
+	x := fixedBits(val) & (math.MaxUint64 >> (64 - kind.bits))
+	return int64(bits.OnesCount64(x))
+}
+
+// Returns the value of any integer type as a Number.
+func integerToBig(typ Type, val any) *big.Int {
+	if kind := typ.fixedKind(); kind != nil {
+		return kind.toBig(val)
+	}
+	return val.(*big.Int)
+}
+
+// Returns the integer value for the type, failing if it is out of range.
+func integerFromBig(typ Type, val *big.Int) (any, error) {
+	kind := typ.fixedKind()
+	if kind == nil {
+		return val, nil
+	} else if out, ok := kind.fromBig(val); ok {
+		return out, nil
+	}
+	return nil, errIntOverflow(val, typ)
+}
+
+func fixedMethods(typ Type, kind *fixedKind) []*Native {
+	types := typ.Set()
+	typeInt := types.Scalar(TypeScalarInt)
+	return []*Native{
+		{
+			Name: "popcount",
+			Type: types.Func(typeInt, typ),
+			Eval: func(rt *Runtime, args []any) (out any, err error) {
+				return kind.popcount(args[0]), nil
+			},
+		},
+		{
+			Name: "rotate_left",
+			Type: types.Func(typ, typ, typeInt),
+			Eval: func(rt *Runtime, args []any) (out any, err error) {
+				return kind.rotate(args[0], args[1].(int64)), nil
+			},
+		},
+		{
+			Name: "rotate_right",
+			Type: types.Func(typ, typ, typeInt),
+			Eval: func(rt *Runtime, args []any) (out any, err error) {
+				return kind.rotate(args[0], -(args[1].(int64) % int64(kind.bits))), nil
+			},
+		},
+	}
+}
@@ -0,0 +1,143 @@
+package code
+
+import (
+	"fmt"
+	"strings"
+
+	"axlab.dev/bit/base"
+)
+
+// Iterates over the items of a list or the entries of a map, in order.
+//
+// For a list, Vars binds either the item or the index and the item. For
+// a map, Vars binds either the key or the key and the value. The loop
+// iterates over the values present when it starts.
+//
+// The value can also be a function with no parameters returning `Option`,
+// which is called for each item until it returns `None`.
+type For struct {
+	Vars  []Var
+	Value Expr
+	Body  Expr
+}
+
+func (expr For) IsExpr() {}
+
+func (expr For) String() string {
+	out := strings.Builder{}
+	out.WriteString("For(")
+	for n, it := range expr.Vars {
+		if n > 0 {
+			out.WriteString(", ")
+		}
+		out.WriteString(string(it.Name))
+	}
+	out.WriteString(fmt.Sprintf(" in %s = %s)", expr.Value, expr.Body))
+	return out.String()
+}
+
+func compileFor(scope *Scope, expr For, span base.Span) (eval EvalFunc, typ Type, err error) {
+	types := scope.Types()
+
+	value, valueType, err := compileExpr(scope, expr.Value)
+	if err != nil {
+		return nil, typ, err
+	}
+
+	if len(expr.Vars) == 0 || len(expr.Vars) > 2 {
+		return nil, typ, errorf(codeInvalidLoop, "loop over `%s` must bind one or two variables", valueType)
+	}
+
+	var vars []Type
+	switch def := valueType.Def().(type) {
+	case TypeList:
+		vars = []Type{def.elem}
+		if len(expr.Vars) == 2 {
+			vars = []Type{types.Scalar(TypeScalarInt), def.elem}
+		}
+	case TypeMap:
+		vars = []Type{def.key, def.val}
+	case TypeFunc:
+		if len(def.params) > 0 || !def.result.isOption() {
+			return nil, typ, errorf(codeInvalidLoop, "cannot iterate over function `%s`, expected no parameters and an Option result", valueType)
+		}
+		if len(expr.Vars) != 1 {
+			return nil, typ, errorf(codeInvalidLoop, "loop over `%s` must bind one variable", valueType)
+		}
+		vars = []Type{def.result.Def().(TypeEnum).args[0]}
+	default:
+		return nil, typ, errorf(codeInvalidLoop, "cannot iterate over value of type `%s`", valueType)
+	}
+
+	loopScope := scope.NewChild()
+	for n, it := range expr.Vars {
+		if it.Type.Valid() && scope.Type(it.Type) != vars[n] {
+			return nil, typ, errorf(codeTypeMismatch, "loop variable `%s` has type `%s`, got `%s`", it.Name, vars[n], it.Type)
+		}
+		varSpan := it.declSpan(span)
+		if _, err := loopScope.declare(Var{Name: it.Name, Type: vars[n]}, varSpan, nil); err != nil {
+			return nil, typ, compileError(varSpan, err)
+		}
+	}
+
+	body, _, err := compileStmt(loopScope, expr.Body)
+	if err != nil {
+		return nil, typ, err
+	}
+	loopScope.checkUnused()
+
+	iterate := func(rt *Runtime, args ...any) error {
+		cleanup := rt.InitScope(loopScope)
+		defer cleanup()
+		copy(rt.topFrame().vars, args)
+		_, err := body(rt)
+		return err
+	}
+
+	pair := len(expr.Vars) == 2
+	eval = func(rt *Runtime) (out any, err error) {
+		val, err := value(rt)
+		if err != nil {
+			return nil, err
+		}
+
+		switch val := val.(type) {
+		case *ListValue:
+			items := append([]any(nil), val.Items...)
+			for n, it := range items {
+				if pair {
+					err = iterate(rt, int64(n), it)
+				} else {
+					err = iterate(rt, it)
+				}
+				if err != nil {
+					return nil, err
+				}
+			}
+		case *MapValue:
+			keys, vals := val.Entries()
+			for n := range keys {
+				if err := iterate(rt, keys[n], vals[n]); err != nil {
+					return nil, err
+				}
+			}
+		default:
+			for {
+				next, err := rt.callValue(val, nil)
+				if err != nil {
+					return nil, err
+				}
+
+				item := next.(EnumValue)
+				if item.Variant == optionNone {
+					break
+				}
+				if err := iterate(rt, item.Value); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return nil, nil
+	}
+	return eval, types.Unit(), nil
+}
@@ -0,0 +1,333 @@
+package code
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Formats a runtime value as text.
+type formatFunc func(rt *Runtime, val any) (string, error)
+
+// Returns the `Show` trait, which overrides how values of a type are
+// formatted. It has a single `show(Self) -> String` method.
+func (set *TypeSet) Show() Type {
+	return set.builtins().show
+}
+
+// Returns the function to format values of the type, as used by Print and
+// string interpolation.
+//
+// Strings and chars are formatted as is at the top level, but quoted when
+// nested in another value. Other values are formatted similarly to their
+// literals. Types implementing `Show` are formatted by its method at any
+// level.
+func (set *TypeSet) formatter(typ Type) (formatFunc, error) {
+	builder := formatBuilder{set: set}
+	return builder.get(typ, false)
+}
+
+type formatBuilder struct {
+	set   *TypeSet
+	cache map[formatKey]*formatFunc
+}
+
+type formatKey struct {
+	typ    Type
+	nested bool
+}
+
+// Returns the formatter for the type, caching it so recursive types use
+// the same formatter.
+func (builder *formatBuilder) get(typ Type, nested bool) (formatFunc, error) {
+	key := formatKey{typ, nested}
+	if fn, ok := builder.cache[key]; ok {
+		return func(rt *Runtime, val any) (string, error) {
+			return (*fn)(rt, val)
+		}, nil
+	}
+
+	if builder.cache == nil {
+		builder.cache = make(map[formatKey]*formatFunc)
+	}
+
+	fn := new(formatFunc)
+	builder.cache[key] = fn
+
+	var err error
+	*fn, err = builder.build(typ, nested)
+	return *fn, err
+}
+
+func (builder *formatBuilder) build(typ Type, nested bool) (formatFunc, error) {
+	set := builder.set
+	show := set.Show()
+
+	if _, isVar := typ.Def().(TypeVar); isVar {
+		return func(rt *Runtime, val any) (string, error) {
+			unreachableGeneric("formatting", typ)
+			return "", nil
+		}, nil
+	}
+
+	if typ == show {
+		return func(rt *Runtime, val any) (string, error) {
+			obj := val.(TraitObject)
+			out, err := rt.call(obj.vtable["show"], rt.rootFrame(), []any{obj.Value})
+			if err != nil {
+				return "", err
+			}
+			return out.(string), nil
+		}, nil
+	}
+
+	if set.Implements(typ, show) {
+		methods, err := set.implMethods(show, typ)
+		if err != nil {
+			return nil, err
+		}
+
+		code := methods["show"]
+		return func(rt *Runtime, val any) (string, error) {
+			out, err := rt.call(code, rt.rootFrame(), []any{val})
+			if err != nil {
+				return "", err
+			}
+			return out.(string), nil
+		}, nil
+	}
+
+	switch def := typ.Def().(type) {
+	case TypeScalar:
+		return formatScalar(def.kind, nested), nil
+
+	case TypeTuple:
+		items, err := builder.list(def.types)
+		if err != nil {
+			return nil, err
+		}
+		return func(rt *Runtime, val any) (string, error) {
+			out, err := formatItems(rt, items, val.([]any))
+			if len(items) == 1 {
+				return "(" + out + ",)", err
+			}
+			return "(" + out + ")", err
+		}, nil
+
+	case TypeList:
+		elem, err := builder.get(def.elem, true)
+		if err != nil {
+			return nil, err
+		}
+		return func(rt *Runtime, val any) (string, error) {
+			list := val.(*ListValue).Items
+			out, err := formatItems(rt, repeat(elem, len(list)), list)
+			return "[" + out + "]", err
+		}, nil
+
+	case TypeMap:
+		key, err := builder.get(def.key, true)
+		if err != nil {
+			return nil, err
+		}
+		value, err := builder.get(def.val, true)
+		if err != nil {
+			return nil, err
+		}
+		return func(rt *Runtime, val any) (string, error) {
+			out := strings.Builder{}
+			out.WriteString("{")
+			keys, vals := val.(*MapValue).Entries()
+			for n := range keys {
+				k, err := key(rt, keys[n])
+				if err != nil {
+					return "", err
+				}
+				v, err := value(rt, vals[n])
+				if err != nil {
+					return "", err
+				}
+				if n > 0 {
+					out.WriteString(", ")
+				}
+				out.WriteString(k)
+				out.WriteString(": ")
+				out.WriteString(v)
+			}
+			out.WriteString("}")
+			return out.String(), nil
+		}, nil
+
+	case TypeRecord:
+		fields := def.Fields()
+		types := make([]Type, len(fields))
+		for n, it := range fields {
+			types[n] = it.Type
+		}
+		items, err := builder.list(types)
+		if err != nil {
+			return nil, err
+		}
+		return func(rt *Runtime, val any) (string, error) {
+			if len(fields) == 0 {
+				return string(def.Name()) + " {}", nil
+			}
+			out := strings.Builder{}
+			out.WriteString(string(def.Name()))
+			out.WriteString(" {")
+			for n, it := range val.([]any) {
+				text, err := items[n](rt, it)
+				if err != nil {
+					return "", err
+				}
+				if n > 0 {
+					out.WriteString(",")
+				}
+				out.WriteString(fmt.Sprintf(" %s: %s", fields[n].Name, text))
+			}
+			out.WriteString(" }")
+			return out.String(), nil
+		}, nil
+
+	case TypeEnum:
+		variants := def.Variants()
+		payload := make([]formatFunc, len(variants))
+		for n, it := range variants {
+			if !it.Type.Valid() {
+				continue
+			}
+
+			var err error
+			if tuple, ok := it.Type.Def().(TypeTuple); ok && len(tuple.types) > 1 {
+				// tuple payloads are formatted as the variant arguments
+				items, err := builder.list(tuple.types)
+				if err != nil {
+					return nil, err
+				}
+				payload[n] = func(rt *Runtime, val any) (string, error) {
+					return formatItems(rt, items, val.([]any))
+				}
+			} else if payload[n], err = builder.get(it.Type, true); err != nil {
+				return nil, err
+			}
+		}
+		return func(rt *Runtime, val any) (string, error) {
+			enum := val.(EnumValue)
+			name := string(variants[enum.Variant].Name)
+			if payload[enum.Variant] == nil {
+				return name, nil
+			}
+			out, err := payload[enum.Variant](rt, enum.Value)
+			return name + "(" + out + ")", err
+		}, nil
+
+	case TypeTrait:
+		// formatters for the dynamic types, built on first use
+		var cacheSync sync.Mutex
+		cache := make(map[Type]formatFunc)
+		return func(rt *Runtime, val any) (string, error) {
+			obj := val.(TraitObject)
+			cacheSync.Lock()
+			fn, ok := cache[obj.Type]
+			cacheSync.Unlock()
+
+			if !ok {
+				var err error
+				if fn, err = set.formatter(obj.Type); err != nil {
+					return "", err
+				}
+				cacheSync.Lock()
+				cache[obj.Type] = fn
+				cacheSync.Unlock()
+			}
+			return fn(rt, obj.Value)
+		}, nil
+
+	case TypeFunc:
+		return func(rt *Runtime, val any) (string, error) {
+			switch fn := val.(type) {
+			case *Native:
+				return fmt.Sprintf("<native %s>", fn.Name), nil
+			default:
+				return fmt.Sprintf("<func %s>", fn.(*FuncValue).code.name), nil
+			}
+		}, nil
+	}
+
+	return func(rt *Runtime, val any) (string, error) {
+		return fmt.Sprint(val), nil
+	}, nil
+}
+
+func (builder *formatBuilder) list(types []Type) (out []formatFunc, err error) {
+	out = make([]formatFunc, len(types))
+	for n, it := range types {
+		if out[n], err = builder.get(it, true); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func formatItems(rt *Runtime, items []formatFunc, values []any) (string, error) {
+	out := strings.Builder{}
+	for n, it := range values {
+		text, err := items[n](rt, it)
+		if err != nil {
+			return "", err
+		}
+		if n > 0 {
+			out.WriteString(", ")
+		}
+		out.WriteString(text)
+	}
+	return out.String(), nil
+}
+
+func repeat(fn formatFunc, count int) []formatFunc {
+	out := make([]formatFunc, count)
+	for n := range out {
+		out[n] = fn
+	}
+	return out
+}
+
+func formatScalar(kind TypeScalarKind, nested bool) formatFunc {
+	switch kind {
+	case TypeScalarUnit:
+		return func(rt *Runtime, val any) (string, error) { return "()", nil }
+	case TypeScalarString:
+		if nested {
+			return func(rt *Runtime, val any) (string, error) { return strconv.Quote(val.(string)), nil }
+		}
+		return func(rt *Runtime, val any) (string, error) { return val.(string), nil }
+	case TypeScalarChar:
+		if nested {
+			return func(rt *Runtime, val any) (string, error) { return strconv.QuoteRune(rune(val.(CharValue))), nil }
+		}
+		return func(rt *Runtime, val any) (string, error) { return val.(CharValue).String(), nil }
+	case TypeScalarFloat:
+		return func(rt *Runtime, val any) (string, error) { return formatFloat(val.(float64)), nil }
+	case TypeScalarNumber:
+		return func(rt *Runtime, val any) (string, error) { return val.(*big.Int).String(), nil }
+	default:
+		return func(rt *Runtime, val any) (string, error) { return fmt.Sprint(val), nil }
+	}
+}
+
+// Returns the shortest representation that parses back to the same value,
+// always including a decimal point or exponent.
+func formatFloat(val float64) string {
+	if math.IsInf(val, 0) || math.IsNaN(val) {
+		return strconv.FormatFloat(val, 'g', -1, 64)
+	}
+
+	out := strconv.FormatFloat(val, 'g', -1, 64)
+	if !strings.ContainsAny(out, ".e") {
+		out += ".0"
+	}
+	return out
+}
@@ -0,0 +1,262 @@
+package code
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"axlab.dev/bit/base"
+)
+
+// Declares a named function in the current scope.
+//
+// Generic functions list their type parameters in `Generics`. The body of
+// a generic function is checked once against the declared type variables
+// and then compiled for each distinct set of type arguments it is called
+// with.
+//
+// Top-level functions marked `Pub` are visible to other modules.
+type Func struct {
+	Name     Id
+	Generics []Type
+	Params   []Var
+	Result   Type
+	Body     Expr
+	Pub      bool
+}
+
+func (expr Func) IsExpr() {}
+
+func (expr Func) String() string {
+	out := strings.Builder{}
+	out.WriteString("Func(")
+	if expr.Pub {
+		out.WriteString("pub ")
+	}
+	out.WriteString(string(expr.Name))
+	if len(expr.Generics) > 0 {
+		out.WriteString("[")
+		for n, it := range expr.Generics {
+			if n > 0 {
+				out.WriteString(", ")
+			}
+			out.WriteString(it.String())
+		}
+		out.WriteString("]")
+	}
+	out.WriteString("(")
+	for n, it := range expr.Params {
+		if n > 0 {
+			out.WriteString(", ")
+		}
+		out.WriteString(fmt.Sprintf("%s: %s", it.Name, it.Type))
+	}
+	out.WriteString(")")
+	if expr.Result.Valid() {
+		out.WriteString(" -> ")
+		out.WriteString(expr.Result.String())
+	}
+	out.WriteString(" = ")
+	out.WriteString(expr.Body.String())
+	out.WriteString(")")
+	return out.String()
+}
+
+// Runtime value for a function.
+type FuncValue struct {
+	code *funcCode
+	env  *stackFrame
+}
+
+func (fn *FuncValue) String() string {
+	return fmt.Sprintf("Func(%s)", fn.code.name)
+}
+
+type funcDef struct {
+	expr  Func
+	span  base.Span
+	scope *Scope
+	typ   Type
+
+	codeSync sync.Mutex
+	codeMap  map[TypeKey]*funcCode
+
+	// number of instances being compiled, nested in one another
+	depth int
+}
+
+// Maximum number of nested instances for a generic function, which stops
+// polymorphic recursion from instantiating it forever.
+const maxInstanceDepth = 64
+
+type funcCode struct {
+	name  Id
+	span  base.Span
+	typ   Type
+	scope *Scope
+	eval  EvalFunc
+	err   error
+}
+
+func newFuncDef(scope *Scope, expr Func, span base.Span) *funcDef {
+	types := scope.Types()
+
+	result := types.Unit()
+	if expr.Result.Valid() {
+		result = expr.Result
+	}
+
+	params := make([]Type, len(expr.Params))
+	for n, it := range expr.Params {
+		params[n] = it.Type
+	}
+
+	return &funcDef{
+		expr:  expr,
+		span:  span,
+		scope: scope,
+		typ:   scope.Type(types.Func(result, params...)),
+	}
+}
+
+func compileFunc(scope *Scope, expr Func, span base.Span) (eval EvalFunc, typ Type, err error) {
+	def := newFuncDef(scope, expr, span)
+	id, err := scope.declare(Var{Name: expr.Name, Type: def.typ}, span, def)
+	if err != nil {
+		return nil, typ, err
+	}
+
+	// for generic functions this checks the body against the declared type
+	// variables, which must hold for any valid instantiation
+	code, err := def.instance(expr.Generics)
+	if err != nil {
+		return nil, typ, err
+	}
+
+	eval = func(rt *Runtime) (out any, err error) {
+		out = &FuncValue{code: code, env: rt.topFrame()}
+		rt.SetVar(id, out)
+		return out, nil
+	}
+	return eval, def.typ, nil
+}
+
+// Compiles a top-level function. The function is declared before compiling
+// the program and its value is set when the program starts.
+func compileTopFunc(decl *topDecl) (eval EvalFunc, typ Type, err error) {
+	if decl.code, err = decl.fn.instance(decl.fn.expr.Generics); err != nil {
+		return nil, typ, err
+	}
+
+	id := decl.id
+	eval = func(rt *Runtime) (out any, err error) {
+		return rt.GetVar(id), nil
+	}
+	return eval, decl.fn.typ, nil
+}
+
+// Returns the compiled function body for the given type arguments.
+func (def *funcDef) instance(args []Type) (*funcCode, error) {
+	types := def.scope.Types()
+	key := types.GetKey(args...)
+
+	def.codeSync.Lock()
+	code, ok := def.codeMap[key]
+	if !ok && def.depth >= maxInstanceDepth {
+		def.codeSync.Unlock()
+		return nil, errorf(codeInstanceDepth, "instances of `%s` are nested more than %d deep", def.expr.Name, maxInstanceDepth)
+	}
+	if !ok {
+		def.depth++
+		code = &funcCode{
+			name: def.expr.Name,
+			span: def.span,
+			typ:  types.Substitute(def.typ, def.expr.Generics, args),
+		}
+		if def.codeMap == nil {
+			def.codeMap = make(map[TypeKey]*funcCode)
+		}
+		def.codeMap[key] = code
+	}
+	def.codeSync.Unlock()
+
+	if ok {
+		return code, code.err
+	}
+
+	code.scope = def.scope.NewChild()
+	code.scope.fn = code
+	code.scope.noWarn = !sameTypes(args, def.expr.Generics)
+	code.scope.typeVars = def.expr.Generics
+	code.scope.typeArgs = args
+	code.eval, code.err = code.compile(def.expr)
+
+	def.codeSync.Lock()
+	def.depth--
+	def.codeSync.Unlock()
+	return code, code.err
+}
+
+// Fails the evaluation of code that depends on a type variable.
+//
+// Generic bodies are compiled against their type variables only to check
+// them. Each call compiles and runs its own instance with the type
+// arguments, so reaching this is a compiler bug.
+func unreachableGeneric(what string, typ Type) {
+	panic(fmt.Sprintf("internal error: %s for generic type `%s` evaluated outside of an instance", what, typ))
+}
+
+func (code *funcCode) compile(expr Func) (eval EvalFunc, err error) {
+	if err := code.typ.checkMapKeys(); err != nil {
+		return nil, fmt.Errorf("in function `%s`: %w", expr.Name, err)
+	}
+
+	fn := code.typ.Def().(TypeFunc)
+	for n, it := range expr.Params {
+		span := it.declSpan(code.span)
+		if _, err := code.scope.declare(Var{Name: it.Name, Type: fn.params[n]}, span, nil); err != nil {
+			return nil, compileError(span, err)
+		}
+	}
+
+	body, bodyType, err := compileStmt(code.scope, expr.Body)
+	if err != nil {
+		if base.AsDiagnostic(err).Code == codeInstanceDepth {
+			// reported at the innermost call, without the context for each instance
+			return nil, err
+		}
+		return nil, fmt.Errorf("in function `%s`: %w", expr.Name, err)
+	}
+	code.scope.checkUnused()
+
+	if !expr.Result.Valid() {
+		inner := body
+		body = func(rt *Runtime) (out any, err error) {
+			_, err = inner(rt)
+			return nil, err
+		}
+	} else if body, err = coerce(code.scope, body, bodyType, fn.result); err != nil {
+		return nil, fmt.Errorf("in function `%s` result: %w", expr.Name, err)
+	}
+
+	eval = func(rt *Runtime) (out any, err error) {
+		out, err = body(rt)
+		if ret, ok := err.(*funcReturn); ok {
+			return ret.value, nil
+		}
+		return out, err
+	}
+	return eval, nil
+}
+
+func sameTypes(a, b []Type) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for n := range a {
+		if a[n] != b[n] {
+			return false
+		}
+	}
+	return true
+}
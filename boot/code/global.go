@@ -0,0 +1,76 @@
+package code
+
+// Top-level variable declared by a Let directly in the program.
+//
+// Globals are declared before compiling the program, so any top-level code
+// can refer to them.
+//
+// Initializers run in source order, except that reading a global which is
+// not yet initialized runs its initializer first. A global depending on its
+// own value is an error: at compile time if it needs its own type, and at
+// run time if the initializer reads it through a function call.
+type globalDef struct {
+	expr  Let
+	scope *Scope
+	decl  *scopeVar
+
+	init      EvalFunc
+	err       error
+	compiling bool
+}
+
+// State of a global in the program frame before its value is set.
+type globalState int
+
+const (
+	globalPending globalState = iota
+	globalRunning
+)
+
+// Compiles the initializer for the global, setting its type. This is done
+// at the first reference to the global or at its declaration.
+func (global *globalDef) compile() error {
+	if global.init != nil || global.err != nil {
+		return global.err
+	}
+
+	name := global.expr.Decl.Name
+	if global.compiling {
+		return errorf(codeCyclicInit, "cyclic initialization of global `%s`", name)
+	}
+
+	global.compiling = true
+	init, decl, err := compileLetInit(global.scope, global.expr)
+	global.compiling = false
+
+	if err != nil {
+		global.err = compileError(global.expr.Init.Span(), err)
+		return global.err
+	}
+
+	global.init = init
+	global.decl.typ = decl.Type
+	return nil
+}
+
+// Returns the value of the global, running its initializer if needed.
+func (global *globalDef) get(rt *Runtime) (out any, err error) {
+	frame := rt.rootFrame()
+	index := global.decl.index
+
+	state, ok := frame.vars[index].(globalState)
+	if !ok {
+		return frame.vars[index], nil
+	} else if state == globalRunning {
+		return nil, errorf(codeCyclicInit, "cyclic initialization of global `%s`", global.expr.Decl.Name)
+	}
+
+	frame.vars[index] = globalRunning
+	if out, err = rt.evalAtRoot(global.init); err != nil {
+		frame.vars[index] = globalPending
+		return nil, err
+	}
+
+	frame.vars[index] = out
+	return out, nil
+}
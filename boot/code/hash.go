@@ -0,0 +1,113 @@
+package code
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/maphash"
+	"math"
+	"math/big"
+)
+
+var hashSeed = maphash.MakeSeed()
+
+// Returns the structural hash for a value of a hashable type.
+func hashValue(typ Type, val any) uint64 {
+	h := maphash.Hash{}
+	h.SetSeed(hashSeed)
+	writeHash(&h, typ, val)
+	return h.Sum64()
+}
+
+func writeHash(h *maphash.Hash, typ Type, val any) {
+	var buffer [8]byte
+	writeUint := func(v uint64) {
+		binary.LittleEndian.PutUint64(buffer[:], v)
+		h.Write(buffer[:])
+	}
+
+	switch def := typ.Def().(type) {
+	case TypeScalar:
+		switch v := val.(type) {
+		case nil:
+			h.WriteByte(0)
+		case bool:
+			if v {
+				h.WriteByte(1)
+			} else {
+				h.WriteByte(0)
+			}
+		case int8, int16, int32, int64, uint8, uint16, uint32, uint64:
+			writeUint(fixedBits(v))
+		case *big.Int:
+			h.WriteByte(byte(v.Sign() + 1))
+			h.Write(v.Bytes())
+		case float64:
+			if v == 0 {
+				v = 0 // normalize negative zero
+			}
+			writeUint(math.Float64bits(v))
+		case string:
+			writeUint(uint64(len(v)))
+			h.WriteString(v)
+		case BytesValue:
+			writeUint(uint64(len(v)))
+			h.WriteString(string(v))
+		case CharValue:
+			writeUint(uint64(v))
+		default:
+			panic(fmt.Sprintf("cannot hash scalar value %#v", val))
+		}
+	case TypeTuple:
+		for n, it := range def.types {
+			writeHash(h, it, val.([]any)[n])
+		}
+	case TypeRecord:
+		for n, it := range def.Fields() {
+			writeHash(h, it.Type, val.([]any)[n])
+		}
+	case TypeEnum:
+		enum := val.(EnumValue)
+		writeUint(uint64(enum.Variant))
+		if variant := def.Variants()[enum.Variant]; variant.Type.Valid() {
+			writeHash(h, variant.Type, enum.Value)
+		}
+	default:
+		panic(fmt.Sprintf("cannot hash value of type `%s`", typ))
+	}
+}
+
+// Returns true if both values of a hashable type are structurally equal.
+func equalValues(typ Type, a, b any) bool {
+	switch def := typ.Def().(type) {
+	case TypeScalar:
+		if na, ok := a.(*big.Int); ok {
+			return na.Cmp(b.(*big.Int)) == 0
+		}
+		return a == b
+	case TypeTuple:
+		for n, it := range def.types {
+			if !equalValues(it, a.([]any)[n], b.([]any)[n]) {
+				return false
+			}
+		}
+		return true
+	case TypeRecord:
+		for n, it := range def.Fields() {
+			if !equalValues(it.Type, a.([]any)[n], b.([]any)[n]) {
+				return false
+			}
+		}
+		return true
+	case TypeEnum:
+		ea, eb := a.(EnumValue), b.(EnumValue)
+		if ea.Variant != eb.Variant {
+			return false
+		}
+		if variant := def.Variants()[ea.Variant]; variant.Type.Valid() {
+			return equalValues(variant.Type, ea.Value, eb.Value)
+		}
+		return true
+	default:
+		panic(fmt.Sprintf("cannot compare values of type `%s`", typ))
+	}
+}
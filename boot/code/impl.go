@@ -0,0 +1,247 @@
+package code
+
+import (
+	"fmt"
+	"strings"
+
+	"axlab.dev/bit/base"
+)
+
+// Implements a trait for a type or, if Trait is not given, declares
+// methods for the type itself.
+//
+// Generic implementations list their type parameters in `Generics`, which
+// must all appear in `Type` (e.g. `impl[T: Show] Show for Pair[T, T]`).
+// Implementations are global to the program and must be declared at the
+// top level.
+type Impl struct {
+	Generics []Type
+	Trait    Type
+	Type     Type
+	Methods  []Func
+}
+
+func (expr Impl) IsExpr() {}
+
+func (expr Impl) String() string {
+	out := strings.Builder{}
+	out.WriteString("Impl(")
+	if len(expr.Generics) > 0 {
+		out.WriteString("[")
+		for n, it := range expr.Generics {
+			if n > 0 {
+				out.WriteString(", ")
+			}
+			out.WriteString(it.String())
+		}
+		out.WriteString("] ")
+	}
+	if expr.Trait.Valid() {
+		out.WriteString(fmt.Sprintf("%s for ", expr.Trait))
+	}
+	out.WriteString(expr.Type.String())
+	for _, it := range expr.Methods {
+		out.WriteString("; ")
+		out.WriteString(it.String())
+	}
+	out.WriteString(")")
+	return out.String()
+}
+
+type implDef struct {
+	trait    Type
+	generics []Type
+	typ      Type
+	methods  map[Id]*funcDef
+}
+
+func compileImpl(scope *Scope, expr Expr) (eval EvalFunc, typ Type, err error) {
+	decl := scope.decls[expr]
+	if decl == nil {
+		return nil, typ, errorf(codeImplPlacement, "impl for `%s` must be at the top level", expr.Value().(Impl).Type)
+	}
+
+	for _, it := range decl.impl {
+		if _, err := it.instance(it.expr.Generics); err != nil {
+			return nil, typ, err
+		}
+	}
+
+	eval = func(rt *Runtime) (out any, err error) {
+		return nil, nil
+	}
+	return eval, scope.Types().Unit(), nil
+}
+
+// Registers the impl with its methods, returning their definitions to be
+// compiled with the impl.
+func declareImpl(scope *Scope, expr Impl, span base.Span) (defs []*funcDef, err error) {
+	if !expr.Trait.Valid() {
+		return declareInherentImpl(scope, expr, span)
+	}
+
+	types := scope.Types()
+	trait, ok := scope.Type(expr.Trait).Def().(TypeTrait)
+	if !ok {
+		return nil, errorf(codeInvalidImpl, "cannot implement non-trait type `%s`", expr.Trait)
+	}
+
+	impl := &implDef{
+		trait:    scope.Type(expr.Trait),
+		generics: expr.Generics,
+		typ:      scope.Type(expr.Type),
+		methods:  make(map[Id]*funcDef),
+	}
+
+	for _, it := range impl.generics {
+		if !impl.typ.HasVars(it) {
+			return nil, errorf(codeInvalidImpl, "impl of `%s` for `%s` does not use type parameter `%s`", trait.Name(), impl.typ, it)
+		}
+	}
+
+	for _, it := range expr.Methods {
+		sig, ok := trait.Method(it.Name)
+		if !ok {
+			return nil, errorf(codeImplMethod, "`%s` is not a method of trait `%s`", it.Name, trait.Name())
+		} else if impl.methods[it.Name] != nil {
+			return nil, errorf(codeImplMethod, "duplicated method `%s` in impl of `%s` for `%s`", it.Name, trait.Name(), impl.typ)
+		} else if len(it.Generics) > 0 {
+			return nil, errorf(codeInvalidImpl, "trait method `%s.%s` cannot be generic", trait.Name(), it.Name)
+		}
+
+		it.Generics = impl.generics
+		def := newFuncDef(scope, it, span)
+		want := types.Substitute(sig.Type, []Type{trait.Self()}, []Type{impl.typ})
+		if def.typ != want {
+			return nil, errorf(codeImplMethod, "method `%s.%s` for `%s` has type `%s`, expected `%s`", trait.Name(), it.Name, impl.typ, def.typ, want)
+		}
+		impl.methods[it.Name] = def
+	}
+
+	for _, it := range trait.Methods() {
+		if impl.methods[it.Name] == nil {
+			return nil, errorf(codeImplMethod, "impl of `%s` for `%s` is missing method `%s`", trait.Name(), impl.typ, it.Name)
+		}
+		defs = append(defs, impl.methods[it.Name])
+	}
+
+	if err := types.addImpl(impl); err != nil {
+		return nil, err
+	}
+	return defs, nil
+}
+
+// Methods declared for a type take the receiver as first parameter. They
+// are added to the method table of the type, or of its generic declaration
+// for the instances matching the Impl type.
+func declareInherentImpl(scope *Scope, expr Impl, span base.Span) (defs []*funcDef, err error) {
+	types := scope.Types()
+
+	implType := scope.Type(expr.Type)
+	for _, it := range expr.Generics {
+		if !implType.HasVars(it) {
+			return nil, errorf(codeInvalidImpl, "impl for `%s` does not use type parameter `%s`", implType, it)
+		}
+	}
+
+	switch implType.Def().(type) {
+	case TypeVar, TypeTrait:
+		return nil, errorf(codeInvalidImpl, "cannot declare methods for `%s`", implType)
+	}
+
+	for _, it := range expr.Methods {
+		if len(it.Params) == 0 || scope.Type(it.Params[0].Type) != implType {
+			return nil, errorf(codeInvalidImpl, "method `%s` for `%s` must take the receiver as first parameter", it.Name, implType)
+		}
+
+		it.Generics = append(append([]Type(nil), expr.Generics...), it.Generics...)
+		def := newFuncDef(scope, it, span)
+		method := &typeMethod{def: def, typ: implType, generics: expr.Generics}
+		if err := types.addMethod(it.Name, method); err != nil {
+			return nil, err
+		}
+		defs = append(defs, def)
+	}
+	return defs, nil
+}
+
+func (set *TypeSet) addImpl(impl *implDef) error {
+	set.implSync.Lock()
+	defer set.implSync.Unlock()
+
+	for _, it := range set.implMap[impl.trait.data] {
+		unifier := set.newUnifier()
+		a := set.Substitute(it.typ, it.generics, unifier.fresh(it.generics))
+		b := set.Substitute(impl.typ, impl.generics, unifier.fresh(impl.generics))
+		if unifier.unify(a, b) == nil {
+			return errorf(codeConflictingImpl, "conflicting impl of `%s` for `%s` and `%s`", impl.trait, it.typ, impl.typ)
+		}
+	}
+
+	if set.implMap == nil {
+		set.implMap = make(map[*typeData][]*implDef)
+	}
+	set.implMap[impl.trait.data] = append(set.implMap[impl.trait.data], impl)
+	return nil
+}
+
+// Finds the implementation of a trait for the type, returning the type
+// arguments for the impl parameters.
+func (set *TypeSet) findImpl(trait, typ Type) (impl *implDef, args []Type, ok bool) {
+	set.implSync.Lock()
+	list := set.implMap[trait.data]
+	set.implSync.Unlock()
+
+next:
+	for _, it := range list {
+		unifier := set.newUnifier()
+		vars := unifier.fresh(it.generics)
+		if unifier.unify(set.Substitute(it.typ, it.generics, vars), typ) != nil {
+			continue
+		}
+
+		args = make([]Type, len(vars))
+		for n, v := range vars {
+			args[n] = unifier.resolve(v)
+			for _, bound := range v.Def().(TypeVar).bounds {
+				if !set.Implements(args[n], bound) {
+					continue next
+				}
+			}
+		}
+		return it, args, true
+	}
+
+	return nil, nil, false
+}
+
+// Returns true if the type implements the trait.
+//
+// A type variable implements the traits it is bound by, and a trait object
+// implements its own trait.
+func (set *TypeSet) Implements(typ, trait Type) bool {
+	if v, ok := typ.Def().(TypeVar); ok {
+		return v.HasBound(trait)
+	}
+	if typ == trait {
+		return true
+	}
+	_, _, ok := set.findImpl(trait, typ)
+	return ok
+}
+
+// Returns the compiled trait methods for a concrete type.
+func (set *TypeSet) implMethods(trait, typ Type) (out map[Id]*funcCode, err error) {
+	impl, args, ok := set.findImpl(trait, typ)
+	if !ok {
+		return nil, errorf(codeNotImplemented, "type `%s` does not implement `%s`", typ, trait)
+	}
+
+	out = make(map[Id]*funcCode, len(impl.methods))
+	for name, def := range impl.methods {
+		if out[name], err = def.instance(args); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,55 @@
+package code
+
+import "strings"
+
+// Interpolated string, where each part is formatted according to its type
+// and concatenated.
+type Interp struct {
+	Parts []Expr
+}
+
+func (expr Interp) IsExpr() {}
+
+func (expr Interp) String() string {
+	out := strings.Builder{}
+	out.WriteString("Interp(")
+	for n, it := range expr.Parts {
+		if n > 0 {
+			out.WriteString(", ")
+		}
+		out.WriteString(it.String())
+	}
+	out.WriteString(")")
+	return out.String()
+}
+
+func compileInterp(scope *Scope, expr Interp) (eval EvalFunc, typ Type, err error) {
+	parts := make([]EvalFunc, len(expr.Parts))
+	formats := make([]formatFunc, len(expr.Parts))
+	for n, it := range expr.Parts {
+		var partType Type
+		if parts[n], partType, err = compileExpr(scope, it); err != nil {
+			return nil, typ, err
+		}
+		if formats[n], err = scope.Types().formatter(partType); err != nil {
+			return nil, typ, err
+		}
+	}
+
+	eval = func(rt *Runtime) (out any, err error) {
+		text := strings.Builder{}
+		for n, it := range parts {
+			val, err := it(rt)
+			if err != nil {
+				return nil, err
+			}
+			part, err := formats[n](rt, val)
+			if err != nil {
+				return nil, err
+			}
+			text.WriteString(part)
+		}
+		return text.String(), nil
+	}
+	return eval, scope.Types().Scalar(TypeScalarString), nil
+}
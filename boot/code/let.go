@@ -5,10 +5,17 @@ import "fmt"
 type Let struct {
 	Decl Var
 	Init Expr
+
+	// Makes a top-level declaration visible to other modules.
+	Pub bool
 }
 
 func (expr Let) IsExpr() {}
 
 func (expr Let) String() string {
-	return fmt.Sprintf("Let(%s: %s = %s)", expr.Decl.Name, expr.Decl.Type, expr.Init)
+	pub := ""
+	if expr.Pub {
+		pub = "pub "
+	}
+	return fmt.Sprintf("Let(%s%s: %s = %s)", pub, expr.Decl.Name, expr.Decl.Type, expr.Init)
 }
@@ -0,0 +1,289 @@
+package code
+
+import (
+	"fmt"
+	"strings"
+)
+
+// List literal. The element Type is required only for an empty list,
+// otherwise it is inferred from the items.
+type List struct {
+	Type  Type
+	Items []Expr
+}
+
+func (expr List) IsExpr() {}
+
+func (expr List) String() string {
+	out := strings.Builder{}
+	out.WriteString("List(")
+	if expr.Type.Valid() {
+		out.WriteString(expr.Type.String())
+		out.WriteString(": ")
+	}
+	for n, it := range expr.Items {
+		if n > 0 {
+			out.WriteString(", ")
+		}
+		out.WriteString(it.String())
+	}
+	out.WriteString(")")
+	return out.String()
+}
+
+// Indexes into a list, map or bytes, i.e. `value[index]`.
+type Index struct {
+	Value Expr
+	Index Expr
+}
+
+func (expr Index) IsExpr() {}
+
+func (expr Index) String() string {
+	return fmt.Sprintf("Index(%s[%s])", expr.Value, expr.Index)
+}
+
+// Returns a new list or bytes with a range of items, i.e. `value[from:to]`.
+// Both bounds are optional.
+type Slice struct {
+	Value Expr
+	From  Expr
+	To    Expr
+}
+
+func (expr Slice) IsExpr() {}
+
+func (expr Slice) String() string {
+	from, to := "", ""
+	if expr.From.Valid() {
+		from = expr.From.String()
+	}
+	if expr.To.Valid() {
+		to = expr.To.String()
+	}
+	return fmt.Sprintf("Slice(%s[%s:%s])", expr.Value, from, to)
+}
+
+// Runtime value for a list.
+type ListValue struct {
+	Items []any
+}
+
+func compileListLiteral(scope *Scope, expr List) (eval EvalFunc, typ Type, err error) {
+	types := scope.Types()
+
+	elem := expr.Type
+	if elem.Valid() {
+		elem = scope.Type(elem)
+	}
+
+	items := make([]EvalFunc, len(expr.Items))
+	for n, it := range expr.Items {
+		item, itemType, err := compileExpr(scope, it)
+		if err != nil {
+			return nil, typ, err
+		}
+		if !elem.Valid() {
+			elem = itemType
+		}
+		if items[n], err = coerce(scope, item, itemType, elem); err != nil {
+			return nil, typ, fmt.Errorf("list item %d: %w", n+1, err)
+		}
+	}
+
+	if !elem.Valid() {
+		return nil, typ, errorf(codeEmptyLiteral, "empty list requires an element type")
+	}
+
+	eval = func(rt *Runtime) (out any, err error) {
+		values, err := evalArgs(rt, items)
+		if err != nil {
+			return nil, err
+		}
+		return &ListValue{Items: values}, nil
+	}
+	return eval, types.List(elem), nil
+}
+
+func compileIndex(scope *Scope, expr Index) (eval EvalFunc, typ Type, err error) {
+	value, valueType, err := compileExpr(scope, expr.Value)
+	if err != nil {
+		return nil, typ, err
+	}
+
+	if _, ok := valueType.Def().(TypeMap); ok {
+		return compileMapIndex(scope, value, valueType, expr.Index)
+	} else if valueType == scope.Types().Scalar(TypeScalarBytes) {
+		return compileBytesIndex(scope, value, expr.Index)
+	}
+
+	list, ok := valueType.Def().(TypeList)
+	if !ok {
+		return nil, typ, errorf(codeInvalidIndex, "cannot index value of type `%s`", valueType)
+	}
+
+	index, err := compileIndexValue(scope, expr.Index)
+	if err != nil {
+		return nil, typ, err
+	}
+
+	eval = func(rt *Runtime) (out any, err error) {
+		val, err := value(rt)
+		if err != nil {
+			return nil, err
+		}
+
+		idx, err := index(rt)
+		if err != nil {
+			return nil, err
+		}
+
+		items := val.(*ListValue).Items
+		pos, err := checkIndex(idx, len(items))
+		if err != nil {
+			return nil, err
+		}
+		return items[pos], nil
+	}
+	return eval, list.elem, nil
+}
+
+func compileSlice(scope *Scope, expr Slice) (eval EvalFunc, typ Type, err error) {
+	value, valueType, err := compileExpr(scope, expr.Value)
+	if err != nil {
+		return nil, typ, err
+	}
+
+	isBytes := valueType == scope.Types().Scalar(TypeScalarBytes)
+	if _, ok := valueType.Def().(TypeList); !ok && !isBytes {
+		return nil, typ, errorf(codeInvalidIndex, "cannot slice value of type `%s`", valueType)
+	}
+
+	var from, to EvalFunc
+	if expr.From.Valid() {
+		if from, err = compileIndexValue(scope, expr.From); err != nil {
+			return nil, typ, err
+		}
+	}
+	if expr.To.Valid() {
+		if to, err = compileIndexValue(scope, expr.To); err != nil {
+			return nil, typ, err
+		}
+	}
+
+	eval = func(rt *Runtime) (out any, err error) {
+		val, err := value(rt)
+		if err != nil {
+			return nil, err
+		}
+
+		if isBytes {
+			bytes := val.(BytesValue)
+			sta, end, err := sliceRange(rt, from, to, len(bytes))
+			if err != nil {
+				return nil, err
+			}
+			return bytes[sta:end], nil
+		}
+
+		items := val.(*ListValue).Items
+		sta, end, err := sliceRange(rt, from, to, len(items))
+		if err != nil {
+			return nil, err
+		}
+
+		slice := append([]any(nil), items[sta:end]...)
+		return &ListValue{Items: slice}, nil
+	}
+	return eval, valueType, nil
+}
+
+// Evaluates the optional bounds for a slice, checking the range.
+func sliceRange(rt *Runtime, from, to EvalFunc, length int) (sta, end int64, err error) {
+	sta, end = 0, int64(length)
+	if from != nil {
+		if idx, err := from(rt); err != nil {
+			return 0, 0, err
+		} else {
+			sta = idx.(int64)
+		}
+	}
+	if to != nil {
+		if idx, err := to(rt); err != nil {
+			return 0, 0, err
+		} else {
+			end = idx.(int64)
+		}
+	}
+
+	if sta < 0 || end > int64(length) || sta > end {
+		return 0, 0, errorf(codeOutOfBounds, "slice out of bounds: range is [%d:%d] but length is %d", sta, end, length)
+	}
+	return sta, end, nil
+}
+
+// Checks an evaluated index against the length.
+func checkIndex(idx any, length int) (int64, error) {
+	if pos := idx.(int64); pos < 0 || pos >= int64(length) {
+		return 0, errorf(codeOutOfBounds, "index out of bounds: index is %d but length is %d", pos, length)
+	} else {
+		return pos, nil
+	}
+}
+
+func compileIndexValue(scope *Scope, expr Expr) (eval EvalFunc, err error) {
+	value, typ, err := compileExpr(scope, expr)
+	if err != nil {
+		return nil, err
+	}
+
+	if typ == scope.Types().Scalar(TypeScalarInt) {
+		return value, nil
+	} else if typ.isInteger() {
+		eval = func(rt *Runtime) (out any, err error) {
+			if out, err = value(rt); err != nil {
+				return nil, err
+			}
+			return numberToInt(integerToBig(typ, out))
+		}
+		return eval, nil
+	}
+	return nil, errorf(codeInvalidIndex, "index must be an integer, got `%s`", typ)
+}
+
+func listMethods(typ Type, list TypeList) []*Native {
+	types := typ.Set()
+	return []*Native{
+		{
+			Name: "len",
+			Type: types.Func(types.Scalar(TypeScalarInt), typ),
+			Eval: func(rt *Runtime, args []any) (out any, err error) {
+				return int64(len(args[0].(*ListValue).Items)), nil
+			},
+		},
+		{
+			Name: "push",
+			Type: types.Func(types.Unit(), typ, list.elem),
+			Eval: func(rt *Runtime, args []any) (out any, err error) {
+				value := args[0].(*ListValue)
+				value.Items = append(value.Items, args[1])
+				return nil, nil
+			},
+		},
+		{
+			Name: "pop",
+			Type: types.Func(list.elem, typ),
+			Eval: func(rt *Runtime, args []any) (out any, err error) {
+				value := args[0].(*ListValue)
+				if len(value.Items) == 0 {
+					return nil, errorf(codeOutOfBounds, "pop from empty list")
+				}
+				last := len(value.Items) - 1
+				out = value.Items[last]
+				value.Items[last] = nil
+				value.Items = value.Items[:last]
+				return out, nil
+			},
+		},
+	}
+}
@@ -0,0 +1,174 @@
+package code
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"axlab.dev/bit/base"
+)
+
+// Parses the source for an expression interpolated in a string literal.
+type ParseFunc func(src string) (Expr, error)
+
+// Returns the expression for the text between the quotes of a string
+// literal.
+//
+// Escape sequences are `\n \r \t \0 \\ \" \' \{ \}` and `\u{...}` with one
+// to six hex digits for a Unicode code point.
+//
+// Text between braces is parsed by the given function and interpolated
+// in the string, which results in an Interp expression. Without a parse
+// function, braces must be escaped.
+//
+// Raw literals are returned as is, with no escapes or interpolation.
+func StrLiteral(text string, raw bool, parse ParseFunc) (Expr, error) {
+	if raw {
+		return ExprNew(Str{Value: text}), nil
+	}
+
+	var (
+		parts []Expr
+		chunk strings.Builder
+	)
+
+	flush := func() {
+		if chunk.Len() > 0 || len(parts) == 0 {
+			parts = append(parts, ExprNew(Str{Value: chunk.String()}))
+			chunk.Reset()
+		}
+	}
+
+	for pos := 0; pos < len(text); {
+		switch chr := text[pos]; chr {
+		case '\\':
+			value, size, err := unescape(text[pos:])
+			if err != nil {
+				return Expr{}, errorf(codeInvalidLiteral, "%v at offset %d in string literal", err, pos)
+			}
+			chunk.WriteString(value)
+			pos += size
+
+		case '{':
+			size := holeSize(text[pos:])
+			if size < 0 {
+				return Expr{}, errorf(codeInvalidLiteral, "unclosed `{` at offset %d in string literal", pos)
+			}
+
+			src := text[pos+1 : pos+size-1]
+			if strings.TrimSpace(src) == "" {
+				return Expr{}, errorf(codeInvalidLiteral, "empty interpolation at offset %d in string literal", pos)
+			} else if parse == nil {
+				return Expr{}, errorf(codeInvalidLiteral, "unescaped `{` at offset %d in string literal", pos)
+			}
+
+			expr, err := parse(src)
+			if err != nil {
+				return Expr{}, fmt.Errorf("in interpolation at offset %d: %w", pos, err)
+			}
+
+			if chunk.Len() > 0 {
+				flush()
+			}
+			parts = append(parts, expr)
+			pos += size
+
+		case '}':
+			return Expr{}, errorf(codeInvalidLiteral, "unmatched `}` at offset %d in string literal", pos)
+
+		default:
+			chunk.WriteByte(chr)
+			pos++
+		}
+	}
+
+	if len(parts) == 0 || chunk.Len() > 0 {
+		flush()
+	}
+
+	if len(parts) == 1 {
+		if _, isStr := parts[0].Value().(Str); isStr {
+			return parts[0], nil
+		}
+	}
+	return ExprNew(Interp{Parts: parts}), nil
+}
+
+// Returns the expression for the text between the quotes of a triple
+// quoted `"""` literal.
+//
+// The text is dedented with the same rules as base.Text: the first line
+// is skipped if blank, the indentation of the first line is removed from
+// all lines, trailing spaces are trimmed, and the text ends with a single
+// line break. This is applied before escapes and interpolation, which are
+// the same as in StrLiteral.
+func TextLiteral(text string, raw bool, parse ParseFunc) (Expr, error) {
+	return StrLiteral(base.Text(text), raw, parse)
+}
+
+// Returns the value for the escape sequence at the start of the text and
+// its length.
+func unescape(text string) (value string, size int, err error) {
+	if len(text) < 2 {
+		return "", 0, errorf(codeInvalidLiteral, "incomplete escape sequence")
+	}
+
+	switch text[1] {
+	case 'n':
+		return "\n", 2, nil
+	case 'r':
+		return "\r", 2, nil
+	case 't':
+		return "\t", 2, nil
+	case '0':
+		return "\x00", 2, nil
+	case '\\', '"', '\'', '{', '}':
+		return text[1:2], 2, nil
+	case 'u':
+		// up to 6 digits, so the closing brace is at most 7 bytes after `\u{`
+		end := -1
+		if strings.HasPrefix(text, `\u{`) {
+			if pos := strings.IndexByte(text[3:min(len(text), 10)], '}'); pos >= 0 {
+				end = 3 + pos
+			}
+		}
+		if end < 0 {
+			return "", 0, errorf(codeInvalidLiteral, "invalid unicode escape, expected `\\u{...}`")
+		}
+
+		digits := text[3:end]
+		code, err := strconv.ParseUint(digits, 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return "", 0, errorf(codeInvalidLiteral, "invalid unicode escape `%s`", text[:end+1])
+		}
+		return string(rune(code)), end + 1, nil
+	default:
+		chr, _ := utf8.DecodeRuneInString(text[1:])
+		return "", 0, errorf(codeInvalidLiteral, "invalid escape sequence `\\%c`", chr)
+	}
+}
+
+// Returns the length of the interpolation starting at the text, including
+// the braces, or -1 if it is not closed.
+//
+// Braces are balanced and nested string literals are skipped.
+func holeSize(text string) int {
+	depth, quote := 0, false
+	for pos := 0; pos < len(text); pos++ {
+		switch chr := text[pos]; {
+		case quote && chr == '\\':
+			pos++
+		case chr == '"':
+			quote = !quote
+		case quote:
+		case chr == '{':
+			depth++
+		case chr == '}':
+			if depth--; depth == 0 {
+				return pos + 1
+			}
+		}
+	}
+	return -1
+}
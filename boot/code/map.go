@@ -0,0 +1,299 @@
+package code
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Map literal. The Key and Value types are required only for an empty
+// map, otherwise they are inferred from the entries.
+type Map struct {
+	Key     Type
+	Value   Type
+	Entries []MapEntry
+}
+
+type MapEntry struct {
+	Key   Expr
+	Value Expr
+}
+
+func (expr Map) IsExpr() {}
+
+func (expr Map) String() string {
+	out := strings.Builder{}
+	out.WriteString("Map(")
+	if expr.Key.Valid() || expr.Value.Valid() {
+		out.WriteString(fmt.Sprintf("%s => %s: ", expr.Key, expr.Value))
+	}
+	for n, it := range expr.Entries {
+		if n > 0 {
+			out.WriteString(", ")
+		}
+		out.WriteString(fmt.Sprintf("%s => %s", it.Key, it.Value))
+	}
+	out.WriteString(")")
+	return out.String()
+}
+
+// Runtime value for a map.
+//
+// Keys are hashed and compared structurally according to the key type and
+// entries are kept in insertion order.
+type MapValue struct {
+	key     Type
+	entries []mapEntry
+	index   map[uint64][]int
+	count   int
+}
+
+type mapEntry struct {
+	key     any
+	val     any
+	deleted bool
+}
+
+func newMapValue(key Type) *MapValue {
+	return &MapValue{key: key, index: make(map[uint64][]int)}
+}
+
+func (m *MapValue) Len() int {
+	return m.count
+}
+
+func (m *MapValue) Get(key any) (val any, ok bool) {
+	if pos, _ := m.find(key); pos >= 0 {
+		return m.entries[pos].val, true
+	}
+	return nil, false
+}
+
+// Sets the value for the key. Updating an existing key keeps its position.
+func (m *MapValue) Insert(key, val any) {
+	pos, hash := m.find(key)
+	if pos >= 0 {
+		m.entries[pos].val = val
+		return
+	}
+
+	m.index[hash] = append(m.index[hash], len(m.entries))
+	m.entries = append(m.entries, mapEntry{key: key, val: val})
+	m.count++
+}
+
+func (m *MapValue) Delete(key any) bool {
+	pos, hash := m.find(key)
+	if pos < 0 {
+		return false
+	}
+
+	m.entries[pos] = mapEntry{deleted: true}
+	m.count--
+
+	list := m.index[hash]
+	for n, it := range list {
+		if it == pos {
+			list = append(list[:n], list[n+1:]...)
+			break
+		}
+	}
+	if len(list) == 0 {
+		delete(m.index, hash)
+	} else {
+		m.index[hash] = list
+	}
+
+	if len(m.entries) > 8 && m.count < len(m.entries)/2 {
+		m.compact()
+	}
+	return true
+}
+
+// Returns the keys and values in insertion order.
+func (m *MapValue) Entries() (keys, vals []any) {
+	keys = make([]any, 0, m.count)
+	vals = make([]any, 0, m.count)
+	for _, it := range m.entries {
+		if !it.deleted {
+			keys = append(keys, it.key)
+			vals = append(vals, it.val)
+		}
+	}
+	return keys, vals
+}
+
+func (m *MapValue) find(key any) (pos int, hash uint64) {
+	hash = hashValue(m.key, key)
+	for _, it := range m.index[hash] {
+		if equalValues(m.key, m.entries[it].key, key) {
+			return it, hash
+		}
+	}
+	return -1, hash
+}
+
+func (m *MapValue) compact() {
+	keys, vals := m.Entries()
+	m.entries = m.entries[:0]
+	m.index = make(map[uint64][]int, len(keys))
+	m.count = 0
+	for n := range keys {
+		m.Insert(keys[n], vals[n])
+	}
+}
+
+func compileMapLiteral(scope *Scope, expr Map) (eval EvalFunc, typ Type, err error) {
+	types := scope.Types()
+
+	keyType, valType := expr.Key, expr.Value
+	if keyType.Valid() {
+		keyType = scope.Type(keyType)
+	}
+	if valType.Valid() {
+		valType = scope.Type(valType)
+	}
+
+	keys := make([]EvalFunc, len(expr.Entries))
+	vals := make([]EvalFunc, len(expr.Entries))
+	for n, it := range expr.Entries {
+		key, itKey, err := compileExpr(scope, it.Key)
+		if err != nil {
+			return nil, typ, err
+		}
+
+		val, itVal, err := compileExpr(scope, it.Value)
+		if err != nil {
+			return nil, typ, err
+		}
+
+		if !keyType.Valid() {
+			keyType = itKey
+		}
+		if !valType.Valid() {
+			valType = itVal
+		}
+
+		if keys[n], err = coerce(scope, key, itKey, keyType); err != nil {
+			return nil, typ, fmt.Errorf("map key %d: %w", n+1, err)
+		}
+		if vals[n], err = coerce(scope, val, itVal, valType); err != nil {
+			return nil, typ, fmt.Errorf("map value %d: %w", n+1, err)
+		}
+	}
+
+	if !keyType.Valid() || !valType.Valid() {
+		return nil, typ, errorf(codeEmptyLiteral, "empty map requires key and value types")
+	}
+
+	typ = types.Map(keyType, valType)
+	if err := typ.checkMapKeys(); err != nil {
+		return nil, typ, err
+	}
+
+	eval = func(rt *Runtime) (out any, err error) {
+		m := newMapValue(keyType)
+		for n := range keys {
+			key, err := keys[n](rt)
+			if err != nil {
+				return nil, err
+			}
+			val, err := vals[n](rt)
+			if err != nil {
+				return nil, err
+			}
+			m.Insert(key, val)
+		}
+		return m, nil
+	}
+	return eval, typ, nil
+}
+
+func compileMapIndex(scope *Scope, value EvalFunc, valueType Type, index Expr) (eval EvalFunc, typ Type, err error) {
+	m := valueType.Def().(TypeMap)
+
+	key, keyType, err := compileExpr(scope, index)
+	if err != nil {
+		return nil, typ, err
+	}
+	if key, err = coerce(scope, key, keyType, m.key); err != nil {
+		return nil, typ, fmt.Errorf("map key: %w", err)
+	}
+
+	eval = func(rt *Runtime) (out any, err error) {
+		val, err := value(rt)
+		if err != nil {
+			return nil, err
+		}
+
+		k, err := key(rt)
+		if err != nil {
+			return nil, err
+		}
+
+		if out, ok := val.(*MapValue).Get(k); ok {
+			return out, nil
+		}
+		return nil, errorf(codeKeyNotFound, "key not found in map")
+	}
+	return eval, m.val, nil
+}
+
+func mapMethods(typ Type, m TypeMap) []*Native {
+	types := typ.Set()
+	typeBool := types.Scalar(TypeScalarBool)
+	return []*Native{
+		{
+			Name: "len",
+			Type: types.Func(types.Scalar(TypeScalarInt), typ),
+			Eval: func(rt *Runtime, args []any) (out any, err error) {
+				return int64(args[0].(*MapValue).Len()), nil
+			},
+		},
+		{
+			Name: "get",
+			Type: types.Func(types.Option(m.val), typ, m.key),
+			Eval: func(rt *Runtime, args []any) (out any, err error) {
+				return optionValue(args[0].(*MapValue).Get(args[1])), nil
+			},
+		},
+		{
+			Name: "has",
+			Type: types.Func(typeBool, typ, m.key),
+			Eval: func(rt *Runtime, args []any) (out any, err error) {
+				_, ok := args[0].(*MapValue).Get(args[1])
+				return ok, nil
+			},
+		},
+		{
+			Name: "insert",
+			Type: types.Func(types.Unit(), typ, m.key, m.val),
+			Eval: func(rt *Runtime, args []any) (out any, err error) {
+				args[0].(*MapValue).Insert(args[1], args[2])
+				return nil, nil
+			},
+		},
+		{
+			Name: "delete",
+			Type: types.Func(typeBool, typ, m.key),
+			Eval: func(rt *Runtime, args []any) (out any, err error) {
+				return args[0].(*MapValue).Delete(args[1]), nil
+			},
+		},
+		{
+			Name: "keys",
+			Type: types.Func(types.List(m.key), typ),
+			Eval: func(rt *Runtime, args []any) (out any, err error) {
+				keys, _ := args[0].(*MapValue).Entries()
+				return &ListValue{Items: keys}, nil
+			},
+		},
+		{
+			Name: "values",
+			Type: types.Func(types.List(m.val), typ),
+			Eval: func(rt *Runtime, args []any) (out any, err error) {
+				_, vals := args[0].(*MapValue).Entries()
+				return &ListValue{Items: vals}, nil
+			},
+		},
+	}
+}
@@ -0,0 +1,97 @@
+package code
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Calls a method on a value, i.e. `value.method(args)`.
+//
+// Methods are resolved through the type of the receiver: first from the
+// methods declared for the type and then from the traits it implements.
+type MethodCall struct {
+	Value  Expr
+	Method Id
+	Args   []Expr
+}
+
+func (expr MethodCall) IsExpr() {}
+
+func (expr MethodCall) String() string {
+	out := strings.Builder{}
+	out.WriteString(fmt.Sprintf("MethodCall(%s.%s", expr.Value, expr.Method))
+	for _, it := range expr.Args {
+		out.WriteString(", ")
+		out.WriteString(it.String())
+	}
+	out.WriteString(")")
+	return out.String()
+}
+
+func compileMethodCall(scope *Scope, expr MethodCall) (eval EvalFunc, typ Type, err error) {
+	types := scope.Types()
+
+	recv, recvType, err := compileExpr(scope, expr.Value)
+	if err != nil {
+		return nil, typ, err
+	}
+
+	if _, isVar := recvType.Def().(TypeVar); !isVar {
+		if method, ok := types.lookupMethod(recvType, expr.Method); ok {
+			if method.native != nil {
+				return compileNativeCall(scope, method.native, recv, expr.Args)
+			}
+			return compileMethod(scope, method.def, recv, recvType, expr.Args)
+		}
+	}
+
+	traits := types.traitsWithMethod(recvType, expr.Method)
+	switch len(traits) {
+	case 0:
+		return nil, typ, errorf(codeUnknownMethod, "type `%s` has no method `%s`", recvType, expr.Method)
+	case 1:
+		sig, _ := traits[0].Def().(TypeTrait).Method(expr.Method)
+		return compileTraitMethod(scope, traits[0], sig, recv, recvType, expr.Args)
+	default:
+		return nil, typ, errorf(codeAmbiguousMethod, "method `%s` for `%s` is ambiguous between traits %s", expr.Method, recvType, joinTypes(traits))
+	}
+}
+
+func compileMethod(scope *Scope, def *funcDef, recv EvalFunc, recvType Type, rest []Expr) (eval EvalFunc, typ Type, err error) {
+	types := scope.Types()
+	unifier := types.newUnifier()
+
+	name := def.expr.Name
+	generics := def.expr.Generics
+	vars := unifier.fresh(generics)
+	fn := types.Substitute(def.typ, generics, vars).Def().(TypeFunc)
+
+	if err := unifier.unify(fn.params[0], recvType); err != nil {
+		return nil, typ, fmt.Errorf("receiver for method `%s`: %w", name, err)
+	}
+
+	args, err := compileArgs(scope, rest, fn.params[1:], unifier)
+	if err != nil {
+		return nil, typ, fmt.Errorf("in call to method `%s`: %w", name, err)
+	}
+	args = append([]EvalFunc{recv}, args...)
+
+	typeArgs, err := resolveTypeArgs(unifier, generics, vars, name)
+	if err != nil {
+		return nil, typ, err
+	}
+
+	code, err := def.instance(typeArgs)
+	if err != nil {
+		return nil, typ, err
+	}
+
+	eval = func(rt *Runtime) (out any, err error) {
+		argValues, err := evalArgs(rt, args)
+		if err != nil {
+			return nil, err
+		}
+		return rt.call(code, rt.rootFrame(), argValues)
+	}
+	return eval, unifier.resolve(fn.result), nil
+}
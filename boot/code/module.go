@@ -0,0 +1,334 @@
+package code
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"axlab.dev/bit/base"
+)
+
+// Path for the module receiving the code appended to the program.
+const MainModule = "main"
+
+// Extension for the source file of a module.
+const ModuleExt = ".bit"
+
+// Code for a single source file in the program.
+//
+// Modules are identified by their path relative to the project root, using
+// `/` as separator and without the file extension. For example, the module
+// `util/text` is the file `util/text.bit`.
+//
+// Each module has its own top-level scope. Other modules are made visible
+// with an Import, and their public declarations are accessed through a
+// Qualified name.
+type Module struct {
+	program  *Program
+	path     string
+	codeList []Expr
+
+	scope      Scope
+	decls      []*topDecl
+	imports    map[Id]*moduleImport
+	importList []*moduleImport
+}
+
+type moduleImport struct {
+	from   *Module
+	module *Module
+	span   base.Span
+}
+
+// Returns the module for the path, creating it if needed.
+func (program *Program) Module(name string) (*Module, error) {
+	name, err := cleanModulePath(name)
+	if err != nil {
+		return nil, err
+	}
+
+	program.codeSync.Lock()
+	defer program.codeSync.Unlock()
+
+	if module := program.modules[name]; module != nil {
+		return module, nil
+	}
+
+	module := &Module{program: program, path: name}
+	module.scope.program = program
+	module.scope.module = module
+	module.scope.frame = &program.scope
+	if program.modules == nil {
+		program.modules = make(map[string]*Module)
+	}
+	program.modules[name] = module
+	return module, nil
+}
+
+// Returns the main module for the program.
+func (program *Program) Main() *Module {
+	module, _ := program.Module(MainModule)
+	return module
+}
+
+func (module *Module) Path() string {
+	return module.path
+}
+
+// Returns the source file for the module, relative to the project root.
+func (module *Module) File() string {
+	file := filepath.FromSlash(module.path) + ModuleExt
+	if root := module.program.Root; root != "" {
+		file = filepath.Join(root, file)
+	}
+	return file
+}
+
+func (module *Module) Append(code ...Expr) {
+	module.program.codeSync.Lock()
+	defer module.program.codeSync.Unlock()
+	module.codeList = append(module.codeList, code...)
+}
+
+// Imports a module, making its public declarations available as a
+// Qualified name with the given Name.
+//
+// The path is relative to the project root or, if it starts with `./` or
+// `../`, to the directory of the importing module. The name defaults to
+// the last component of the path.
+//
+// Imports must be at the top level of a module, and modules cannot import
+// each other in a cycle.
+type Import struct {
+	Path string
+	Name Id
+}
+
+func (expr Import) IsExpr() {}
+
+func (expr Import) String() string {
+	if expr.Name != "" {
+		return fmt.Sprintf("Import(%s as %s)", expr.Path, expr.Name)
+	}
+	return fmt.Sprintf("Import(%s)", expr.Path)
+}
+
+// Name for a public declaration in an imported module.
+type Qualified struct {
+	Module Id
+	Name   Id
+}
+
+func (expr Qualified) IsExpr() {}
+
+func (expr Qualified) String() string {
+	return fmt.Sprintf("Qualified(%s.%s)", expr.Module, expr.Name)
+}
+
+// Resolves an import path relative to the importing module.
+func resolveModulePath(from, name string) (string, error) {
+	full := name
+	if strings.HasPrefix(name, "./") || strings.HasPrefix(name, "../") {
+		full = path.Join(path.Dir(from), name)
+	}
+	if !validModulePath(full) {
+		return "", errorf(codeInvalidImport, "invalid module path `%s`", name)
+	}
+	return path.Clean(full), nil
+}
+
+func cleanModulePath(name string) (string, error) {
+	if !validModulePath(name) {
+		return "", errorf(codeInvalidImport, "invalid module path `%s`", name)
+	}
+	return path.Clean(name), nil
+}
+
+// Module paths must be relative and inside the project root.
+func validModulePath(name string) bool {
+	clean := path.Clean(name)
+	return name != "" && clean != "." && !path.IsAbs(clean) && clean != ".." && !strings.HasPrefix(clean, "../")
+}
+
+func (module *Module) declareImport(expr Import, span base.Span) error {
+	target, err := resolveModulePath(module.path, expr.Path)
+	if err != nil {
+		return err
+	}
+
+	imported := module.program.modules[target]
+	if imported == nil {
+		if module.program.Root != "" {
+			file := filepath.Join(module.program.Root, filepath.FromSlash(target)+ModuleExt)
+			return errorf(codeModuleNotFound, "module `%s` not found, expected file `%s`", target, file)
+		}
+		return errorf(codeModuleNotFound, "module `%s` not found", target)
+	}
+
+	name := expr.Name
+	if name == "" {
+		name = Id(path.Base(target))
+	}
+
+	if first := module.imports[name]; first != nil {
+		return &base.Diagnostic{
+			Code:    codeRedeclared,
+			Message: fmt.Sprintf("module `%s` is already imported", name),
+			Related: relatedAt(first.span, "first imported here"),
+		}
+	}
+
+	entry := &moduleImport{from: module, module: imported, span: span}
+	if module.imports == nil {
+		module.imports = make(map[Id]*moduleImport)
+	}
+	module.imports[name] = entry
+	module.importList = append(module.importList, entry)
+	return nil
+}
+
+// Returns the program modules sorted by path, with the main module last.
+func (program *Program) sortedModules() []*Module {
+	var out []*Module
+	for _, it := range program.modules {
+		out = append(out, it)
+	}
+	sort.Slice(out, func(a, b int) bool {
+		if isMain := out[a].path == MainModule; isMain != (out[b].path == MainModule) {
+			return !isMain
+		}
+		return out[a].path < out[b].path
+	})
+	return out
+}
+
+// Returns the order to run the modules, with each module after the ones it
+// imports. Fails if there is an import cycle.
+func moduleOrder(modules []*Module) (order []*Module, err error) {
+	const (
+		visiting = iota + 1
+		visited
+	)
+
+	state := make(map[*Module]int)
+	var stack []*moduleImport
+	var visit func(module *Module) error
+	visit = func(module *Module) error {
+		state[module] = visiting
+		for _, it := range module.importList {
+			switch state[it.module] {
+			case visiting:
+				return importCycle(stack, it)
+			case 0:
+				stack = append(stack, it)
+				if err := visit(it.module); err != nil {
+					return err
+				}
+				stack = stack[:len(stack)-1]
+			}
+		}
+		state[module] = visited
+		order = append(order, module)
+		return nil
+	}
+
+	for _, it := range modules {
+		if state[it] == 0 {
+			if err := visit(it); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return order, nil
+}
+
+// Reports the import closing a cycle, with the other imports in the cycle
+// as related locations.
+func importCycle(stack []*moduleImport, last *moduleImport) error {
+	cycle := []*moduleImport{last}
+	for n, it := range stack {
+		if it.from == last.module {
+			cycle = append(stack[n:len(stack):len(stack)], last)
+			break
+		}
+	}
+
+	names := []string{fmt.Sprintf("`%s`", cycle[0].from.path)}
+	var related []base.Related
+	for _, it := range cycle {
+		names = append(names, fmt.Sprintf("`%s`", it.module.path))
+		if it != last {
+			msg := fmt.Sprintf("`%s` imports `%s`", it.from.path, it.module.path)
+			related = append(related, relatedAt(it.span, msg)...)
+		}
+	}
+
+	return compileError(last.span, &base.Diagnostic{
+		Code:    codeImportCycle,
+		Message: fmt.Sprintf("import cycle: %s", strings.Join(names, " -> ")),
+		Related: related,
+	})
+}
+
+func compileImport(scope *Scope, expr Expr) (eval EvalFunc, typ Type, err error) {
+	if scope.decls[expr] == nil {
+		return nil, typ, errorf(codeInvalidImport, "import of `%s` must be at the top level of a module", expr.Value().(Import).Path)
+	}
+
+	eval = func(rt *Runtime) (out any, err error) {
+		return nil, nil
+	}
+	return eval, scope.Types().Unit(), nil
+}
+
+// Resolves a qualified name to the declaration in the imported module,
+// marking it as used.
+func (scope *Scope) resolveQualified(expr Qualified) (decl *scopeVar, err error) {
+	module := scope.getRoot().module
+	if module == nil || module.imports[expr.Module] == nil {
+		return nil, errorf(codeUndefined, "module `%s` is not imported", expr.Module)
+	}
+
+	imported := module.imports[expr.Module].module
+	decl, found := imported.scope.tryResolve(expr.Name)
+	if !found {
+		return nil, errorf(codeUndefined, "`%s` not found in module `%s`", expr.Name, imported.path)
+	}
+
+	if !decl.pub {
+		return nil, &base.Diagnostic{
+			Code:    codePrivate,
+			Message: fmt.Sprintf("`%s` is private to module `%s`", expr.Name, imported.path),
+			Related: relatedAt(decl.span, "declared here"),
+		}
+	}
+
+	decl.used = true
+	return decl, nil
+}
+
+func compileQualified(scope *Scope, expr Qualified) (eval EvalFunc, typ Type, err error) {
+	decl, err := scope.resolveQualified(expr)
+	if err != nil {
+		return nil, typ, err
+	}
+
+	if decl.fn != nil && len(decl.fn.expr.Generics) > 0 {
+		return nil, typ, errorf(codeGenericValue, "generic function `%s.%s` can only be called", expr.Module, expr.Name)
+	}
+
+	if global := decl.global; global != nil {
+		if err := global.compile(); err != nil {
+			return nil, typ, err
+		}
+		return global.get, decl.typ, nil
+	}
+
+	index := decl.index
+	eval = func(rt *Runtime) (out any, err error) {
+		return rt.rootFrame().vars[index], nil
+	}
+	return eval, decl.typ, nil
+}
@@ -0,0 +1,59 @@
+package code
+
+import "fmt"
+
+// Function implemented in Go, such as the builtin methods.
+//
+// As an expression, it evaluates to the function value. If the function
+// returns a `Result[T, Error]`, then Eval returns the `T` value and any
+// error is returned as an `Err` value instead of failing the evaluation.
+type Native struct {
+	Name Id
+	Type Type
+	Eval func(rt *Runtime, args []any) (out any, err error)
+}
+
+func (fn *Native) IsExpr() {}
+
+func (fn *Native) String() string {
+	return fmt.Sprintf("Native(%s: %s)", fn.Name, fn.Type)
+}
+
+func (fn *Native) call(rt *Runtime, args []any) (out any, err error) {
+	out, err = fn.Eval(rt, args)
+	if result := fn.Type.Def().(TypeFunc).result; result.isResult() {
+		if result.Def().(TypeEnum).args[1] == result.Set().Error() {
+			return resultValue(out, err), nil
+		}
+	}
+	return out, err
+}
+
+func compileNative(scope *Scope, fn *Native) (eval EvalFunc, typ Type, err error) {
+	if _, ok := fn.Type.Def().(TypeFunc); !ok {
+		return nil, typ, errorf(codeNotCallable, "native `%s` is not a function: %s", fn.Name, fn.Type)
+	}
+
+	eval = func(rt *Runtime) (out any, err error) {
+		return fn, nil
+	}
+	return eval, fn.Type, nil
+}
+
+func compileNativeCall(scope *Scope, fn *Native, recv EvalFunc, rest []Expr) (eval EvalFunc, typ Type, err error) {
+	sig := fn.Type.Def().(TypeFunc)
+	args, err := compileArgs(scope, rest, sig.params[1:], nil)
+	if err != nil {
+		return nil, typ, fmt.Errorf("in call to `%s`: %w", fn.Name, err)
+	}
+	args = append([]EvalFunc{recv}, args...)
+
+	eval = func(rt *Runtime) (out any, err error) {
+		argValues, err := evalArgs(rt, args)
+		if err != nil {
+			return nil, err
+		}
+		return fn.call(rt, argValues)
+	}
+	return eval, sig.result, nil
+}
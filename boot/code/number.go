@@ -1,7 +1,15 @@
 package code
 
-import "fmt"
+import (
+	"fmt"
+	"math/big"
+)
 
+// Literal for a `Number` value.
+//
+// At runtime, `Number` is an arbitrary-precision integer represented as
+// a `*big.Int`, while `Int` is a 64-bit integer represented as `int64`.
+// Number values are immutable, so operations always allocate a result.
 type Number struct {
 	Value int64
 }
@@ -19,3 +27,38 @@ func (typeNumber) IsType() {}
 func (typeNumber) String() string {
 	return "Number"
 }
+
+func errIntOverflow(val, typ any) error {
+	return errorf(codeIntOverflow, "integer overflow: `%v` does not fit in `%v`", val, typ)
+}
+
+// Converts a Number value to Int, failing if it is out of range.
+func numberToInt(val *big.Int) (int64, error) {
+	if !val.IsInt64() {
+		return 0, errIntOverflow(val, "Int")
+	}
+	return val.Int64(), nil
+}
+
+func intToNumber(val int64) *big.Int {
+	return big.NewInt(val)
+}
+
+// Returns true for `Number` and the fixed-width integer types.
+func (typ Type) isInteger() bool {
+	if scalar, ok := typ.Def().(TypeScalar); ok {
+		return scalar.kind == TypeScalarNumber || fixedKinds[scalar.kind] != nil
+	}
+	return false
+}
+
+// Returns an eval that promotes an `Int` value to `Number`. This never
+// fails, so it is also done implicitly when coercing.
+func promoteInt(eval EvalFunc) EvalFunc {
+	return func(rt *Runtime) (out any, err error) {
+		if out, err = eval(rt); err != nil {
+			return nil, err
+		}
+		return intToNumber(out.(int64)), nil
+	}
+}
@@ -0,0 +1,168 @@
+package code
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"axlab.dev/bit/base"
+)
+
+// Integer literal of any size, with an optional type suffix such as `u8`.
+// Without a suffix, the literal is a `Number`.
+type Integer struct {
+	Value  *big.Int
+	Suffix string
+}
+
+func (expr Integer) IsExpr() {}
+
+func (expr Integer) String() string {
+	return fmt.Sprintf("Integer(%s%s)", expr.Value, expr.Suffix)
+}
+
+// Literal for a `Float` value.
+type Float struct {
+	Value float64
+}
+
+func (expr Float) IsExpr() {}
+
+func (expr Float) String() string {
+	return fmt.Sprintf("Float(%s)", strconv.FormatFloat(expr.Value, 'g', -1, 64))
+}
+
+var integerSuffixes = map[string]TypeScalarKind{
+	"i8":  TypeScalarI8,
+	"i16": TypeScalarI16,
+	"i32": TypeScalarI32,
+	"i64": TypeScalarI64,
+	"u8":  TypeScalarU8,
+	"u16": TypeScalarU16,
+	"u32": TypeScalarU32,
+	"u64": TypeScalarU64,
+}
+
+// Returns the expression for a numeric literal at the given location.
+//
+// Integers can be decimal or use the `0x`, `0o` and `0b` prefixes, and can
+// have an integer type suffix. Decimal literals with a fraction, exponent
+// or an `f` suffix are `Float`. Digits can be separated by `_`.
+//
+// Range errors for the suffix type are reported when compiling.
+func NumberLiteral(span base.Span, text string) (Expr, error) {
+	value, err := parseNumber(text)
+	if err != nil {
+		return Expr{}, &CompileError{Span: span, Err: err}
+	}
+	return ExprAt(span, value), nil
+}
+
+func parseNumber(text string) (ExprValue, error) {
+	digits, base, prefix := text, 10, ""
+	if len(text) > 2 && text[0] == '0' {
+		switch text[1] {
+		case 'x':
+			base, prefix = 16, "hexadecimal "
+		case 'o':
+			base, prefix = 8, "octal "
+		case 'b':
+			base, prefix = 2, "binary "
+		}
+		if base != 10 {
+			digits = text[2:]
+		}
+	}
+
+	isDigit := func(chr byte) bool {
+		switch {
+		case base == 16:
+			return '0' <= chr && chr <= '9' || 'a' <= chr && chr <= 'f' || 'A' <= chr && chr <= 'F'
+		default:
+			return '0' <= chr && chr <= '9'
+		}
+	}
+
+	// split the suffix and check the digits
+	isFloat, suffix := false, ""
+	for n := 0; n < len(digits); n++ {
+		chr := digits[n]
+		switch {
+		case isDigit(chr):
+			if digit := int(chr - '0'); base < 10 && digit >= base {
+				return nil, errorf(codeInvalidLiteral, "invalid digit `%c` in %sliteral `%s`", chr, prefix, text)
+			}
+			continue
+		case chr == '_':
+			if n == 0 || n == len(digits)-1 || !isDigit(digits[n+1]) {
+				return nil, errorf(codeInvalidLiteral, "invalid `_` separator in numeric literal `%s`", text)
+			}
+			continue
+		case base == 10 && (chr == '.' || chr == 'e' || chr == 'E'):
+			isFloat = true
+			if chr != '.' && n+1 < len(digits) && (digits[n+1] == '-' || digits[n+1] == '+') {
+				n++
+			}
+			continue
+		}
+		digits, suffix = digits[:n], digits[n:]
+		break
+	}
+
+	digits = strings.ReplaceAll(digits, "_", "")
+	if digits == "" {
+		return nil, errorf(codeInvalidLiteral, "invalid numeric literal `%s`", text)
+	}
+
+	if isFloat || suffix == "f" {
+		if base != 10 || (suffix != "" && suffix != "f") {
+			return nil, errorf(codeInvalidLiteral, "invalid suffix `%s` for float literal `%s`", suffix, text)
+		}
+		value, err := strconv.ParseFloat(digits, 64)
+		if err != nil {
+			return nil, errorf(codeInvalidLiteral, "invalid float literal `%s`", text)
+		}
+		return Float{Value: value}, nil
+	}
+
+	if _, ok := integerSuffixes[suffix]; suffix != "" && !ok {
+		return nil, errorf(codeInvalidLiteral, "invalid suffix `%s` for integer literal `%s`", suffix, text)
+	}
+
+	value, ok := new(big.Int).SetString(digits, base)
+	if !ok {
+		return nil, errorf(codeInvalidLiteral, "invalid integer literal `%s`", text)
+	}
+	return Integer{Value: value, Suffix: suffix}, nil
+}
+
+func compileInteger(scope *Scope, expr Integer) (eval EvalFunc, typ Type, err error) {
+	types := scope.Types()
+
+	var value any = expr.Value
+	typ = types.Scalar(TypeScalarNumber)
+	if expr.Suffix != "" {
+		kind, ok := integerSuffixes[expr.Suffix]
+		if !ok {
+			return nil, typ, errorf(codeInvalidLiteral, "invalid integer suffix `%s`", expr.Suffix)
+		}
+
+		typ = types.Scalar(kind)
+		if value, ok = typ.fixedKind().fromBig(expr.Value); !ok {
+			return nil, typ, errorf(codeLiteralRange, "integer literal `%s` out of range for `%s`", expr.Value, typ)
+		}
+	}
+
+	eval = func(rt *Runtime) (out any, err error) {
+		return value, nil
+	}
+	return eval, typ, nil
+}
+
+func compileFloat(scope *Scope, expr Float) (eval EvalFunc, typ Type, err error) {
+	eval = func(rt *Runtime) (out any, err error) {
+		return expr.Value, nil
+	}
+	return eval, scope.Types().Scalar(TypeScalarFloat), nil
+}
@@ -0,0 +1,239 @@
+package code
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"axlab.dev/bit/base"
+)
+
+type BinaryOp string
+
+const (
+	OpAdd BinaryOp = "+"
+	OpSub BinaryOp = "-"
+	OpMul BinaryOp = "*"
+	OpDiv BinaryOp = "/"
+	OpRem BinaryOp = "%"
+
+	OpWrapAdd BinaryOp = "+%"
+	OpWrapSub BinaryOp = "-%"
+	OpWrapMul BinaryOp = "*%"
+
+	OpAnd BinaryOp = "&"
+	OpOr  BinaryOp = "|"
+	OpXor BinaryOp = "^"
+	OpShl BinaryOp = "<<"
+	OpShr BinaryOp = ">>"
+)
+
+// Binary operator expression.
+//
+// Arithmetic operators are defined for integers of the same type, and
+// for mixing `Int` and `Number`, which promotes the `Int` operand to a
+// `Number` result. Operations on fixed-width integers fail on overflow,
+// except for the wrapping operators `+% -% *%`. Division truncates
+// towards zero.
+//
+// Bitwise operators are defined for fixed-width integers of the same type.
+// The shift count can be any integer, but must be less than the bit width.
+type Binary struct {
+	Op  BinaryOp
+	Lhs Expr
+	Rhs Expr
+}
+
+func (expr Binary) IsExpr() {}
+
+func (expr Binary) String() string {
+	return fmt.Sprintf("Binary(%s %s %s)", expr.Lhs, expr.Op, expr.Rhs)
+}
+
+func compileBinary(scope *Scope, expr Binary) (eval EvalFunc, typ Type, err error) {
+	lhs, lhsType, err := compileExpr(scope, expr.Lhs)
+	if err != nil {
+		return nil, typ, err
+	}
+
+	rhs, rhsType, err := compileExpr(scope, expr.Rhs)
+	if err != nil {
+		return nil, typ, err
+	}
+
+	types := scope.Types()
+	typeInt, typeNum := types.Scalar(TypeScalarInt), types.Scalar(TypeScalarNumber)
+	kind := lhsType.fixedKind()
+	errUndefined := errorf(codeInvalidOperator, "operator `%s` is not defined for `%s` and `%s`", expr.Op, lhsType, rhsType)
+
+	var op func(a, b any) (any, error)
+	switch expr.Op {
+	case OpAdd, OpSub, OpMul, OpDiv, OpRem:
+		if lhsType == rhsType && kind != nil {
+			typ = lhsType
+			op = func(a, b any) (any, error) { return kind.checked(expr.Op, typ, a, b) }
+		} else if (lhsType == typeInt || lhsType == typeNum) && (rhsType == typeInt || rhsType == typeNum) {
+			typ = typeNum
+			if lhsType == typeInt {
+				lhs = promoteInt(lhs)
+			}
+			if rhsType == typeInt {
+				rhs = promoteInt(rhs)
+			}
+			fn := numberOps[expr.Op]
+			op = func(a, b any) (any, error) { return fn(a.(*big.Int), b.(*big.Int)) }
+		} else {
+			return nil, typ, errUndefined
+		}
+
+	case OpWrapAdd, OpWrapSub, OpWrapMul, OpAnd, OpOr, OpXor:
+		if lhsType != rhsType || kind == nil {
+			return nil, typ, errUndefined
+		}
+		typ = lhsType
+		op = func(a, b any) (any, error) { return kind.wrapping(expr.Op, a, b), nil }
+
+	case OpShl, OpShr:
+		if kind == nil || !rhsType.isInteger() {
+			return nil, typ, errUndefined
+		}
+		typ = lhsType
+		op = func(a, b any) (any, error) { return kind.shift(expr.Op, typ, a, integerToBig(rhsType, b)) }
+
+	default:
+		return nil, typ, errorf(codeInvalidOperator, "invalid binary operator `%s`", expr.Op)
+	}
+
+	eval = func(rt *Runtime) (out any, err error) {
+		a, err := lhs(rt)
+		if err != nil {
+			return nil, err
+		}
+		b, err := rhs(rt)
+		if err != nil {
+			return nil, err
+		}
+		return op(a, b)
+	}
+	return eval, typ, nil
+}
+
+type UnaryOp string
+
+const (
+	OpNeg    UnaryOp = "-"
+	OpBitNot UnaryOp = "~"
+)
+
+// Unary operator expression.
+//
+// Negation is defined for `Number` and signed integers, failing on
+// overflow. The bitwise complement is defined for fixed-width integers.
+type Unary struct {
+	Op    UnaryOp
+	Value Expr
+}
+
+func (expr Unary) IsExpr() {}
+
+func (expr Unary) String() string {
+	return fmt.Sprintf("Unary(%s%s)", expr.Op, expr.Value)
+}
+
+func compileUnary(scope *Scope, expr Unary) (eval EvalFunc, typ Type, err error) {
+	value, typ, err := compileExpr(scope, expr.Value)
+	if err != nil {
+		return nil, typ, err
+	}
+
+	kind := typ.fixedKind()
+	errUndefined := errorf(codeInvalidOperator, "operator `%s` is not defined for `%s`", expr.Op, typ)
+
+	var op func(v any) (any, error)
+	switch expr.Op {
+	case OpNeg:
+		if typ == scope.Types().Scalar(TypeScalarNumber) {
+			op = func(v any) (any, error) { return new(big.Int).Neg(v.(*big.Int)), nil }
+		} else if kind != nil && kind.signed {
+			op = func(v any) (any, error) {
+				if x := int64(fixedBits(v)); x == kind.min() {
+					return nil, errIntOverflow(fmt.Sprintf("-(%d)", x), typ)
+				} else {
+					return kind.wrap(uint64(-x)), nil
+				}
+			}
+		} else {
+			return nil, typ, errUndefined
+		}
+
+	case OpBitNot:
+		if kind == nil {
+			return nil, typ, errUndefined
+		}
+		op = func(v any) (any, error) { return kind.wrap(^fixedBits(v)), nil }
+
+	default:
+		return nil, typ, errorf(codeInvalidOperator, "invalid unary operator `%s`", expr.Op)
+	}
+
+	eval = func(rt *Runtime) (out any, err error) {
+		val, err := value(rt)
+		if err != nil {
+			return nil, err
+		}
+		return op(val)
+	}
+	return eval, typ, nil
+}
+
+// Shared error for division by zero, which has no formatted arguments.
+var errDivByZero error = &base.Diagnostic{Code: codeDivByZero, Message: "division by zero"}
+
+var numberOps = map[BinaryOp]func(a, b *big.Int) (*big.Int, error){
+	OpAdd: func(a, b *big.Int) (*big.Int, error) { return new(big.Int).Add(a, b), nil },
+	OpSub: func(a, b *big.Int) (*big.Int, error) { return new(big.Int).Sub(a, b), nil },
+	OpMul: func(a, b *big.Int) (*big.Int, error) { return new(big.Int).Mul(a, b), nil },
+	OpDiv: func(a, b *big.Int) (*big.Int, error) {
+		if b.Sign() == 0 {
+			return nil, errDivByZero
+		}
+		return new(big.Int).Quo(a, b), nil
+	},
+	OpRem: func(a, b *big.Int) (*big.Int, error) {
+		if b.Sign() == 0 {
+			return nil, errDivByZero
+		}
+		return new(big.Int).Rem(a, b), nil
+	},
+}
+
+// Checked operations for 64-bit signed integers, returning false on overflow.
+var intOps = map[BinaryOp]func(a, b int64) (int64, bool){
+	OpAdd: func(a, b int64) (int64, bool) {
+		if (b > 0 && a > math.MaxInt64-b) || (b < 0 && a < math.MinInt64-b) {
+			return 0, false
+		}
+		return a + b, true
+	},
+	OpSub: func(a, b int64) (int64, bool) {
+		if (b < 0 && a > math.MaxInt64+b) || (b > 0 && a < math.MinInt64+b) {
+			return 0, false
+		}
+		return a - b, true
+	},
+	OpMul: func(a, b int64) (int64, bool) {
+		if a != 0 && ((a*b)/a != b || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64)) {
+			return 0, false
+		}
+		return a * b, true
+	},
+	OpDiv: func(a, b int64) (int64, bool) {
+		if a == math.MinInt64 && b == -1 {
+			return 0, false
+		}
+		return a / b, true
+	},
+	OpRem: func(a, b int64) (int64, bool) {
+		return a % b, true
+	},
+}
@@ -0,0 +1,365 @@
+package code
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Formats the arguments according to a printf-style format string, i.e.
+// `format(fmt, args...)`. With `Print` set, the result is written to the
+// output instead, i.e. `printf(fmt, args...)`.
+//
+// Specifiers are `%[flags][width][.precision]verb`:
+//
+//   - flags: `-` aligns left, `^` centers, `0` pads numbers with zeros,
+//     `+` always shows the sign, and `#` adds the radix prefix.
+//   - `v` formats any value as Print does.
+//   - `s` is for strings and chars, with precision as the max length.
+//   - `d`, `x`, `X`, `o` and `b` are integers with the respective radix,
+//     with `x` and `X` also accepting bytes.
+//   - `f`, `e` and `g` are floats, with precision as the number of digits.
+//   - `c` is for chars.
+//   - `%%` is a literal percent sign.
+//
+// Width and precision are limited to formatMaxWidth.
+//
+// When the format is a string literal, it is checked against the argument
+// types at compile time. Otherwise, it is checked when evaluated.
+type Format struct {
+	Format Expr
+	Args   []Expr
+	Print  bool
+}
+
+func (expr Format) IsExpr() {}
+
+func (expr Format) String() string {
+	out := strings.Builder{}
+	if expr.Print {
+		out.WriteString("Printf(")
+	} else {
+		out.WriteString("Format(")
+	}
+	out.WriteString(expr.Format.String())
+	for _, it := range expr.Args {
+		out.WriteString(", ")
+		out.WriteString(it.String())
+	}
+	out.WriteString(")")
+	return out.String()
+}
+
+// Maximum width and precision for a format specifier.
+const formatMaxWidth = 1000
+
+type formatPiece struct {
+	text string
+	spec *formatSpec
+}
+
+type formatSpec struct {
+	verb      byte
+	left      bool
+	center    bool
+	zero      bool
+	plus      bool
+	prefix    bool
+	width     int
+	precision int
+}
+
+func compileFormat(scope *Scope, expr Format) (eval EvalFunc, typ Type, err error) {
+	types := scope.Types()
+	typeStr := types.Scalar(TypeScalarString)
+
+	format, formatType, err := compileExpr(scope, expr.Format)
+	if err != nil {
+		return nil, typ, err
+	}
+	if formatType != typeStr {
+		return nil, typ, errorf(codeInvalidFormat, "format must be a `String`, got `%s`", formatType)
+	}
+
+	args := make([]EvalFunc, len(expr.Args))
+	argTypes := make([]Type, len(expr.Args))
+	defaults := make([]formatFunc, len(expr.Args))
+	for n, it := range expr.Args {
+		if args[n], argTypes[n], err = compileExpr(scope, it); err != nil {
+			return nil, typ, err
+		}
+		if defaults[n], err = types.formatter(argTypes[n]); err != nil {
+			return nil, typ, err
+		}
+	}
+
+	var pieces []formatPiece
+	if literal, ok := expr.Format.Value().(Str); ok {
+		if pieces, err = parseFormat(literal.Value); err == nil {
+			err = checkFormat(pieces, argTypes)
+		}
+		if err != nil {
+			return nil, typ, err
+		}
+	}
+
+	eval = func(rt *Runtime) (out any, err error) {
+		current := pieces
+		if current == nil {
+			text, err := format(rt)
+			if err != nil {
+				return nil, err
+			}
+			if current, err = parseFormat(text.(string)); err == nil {
+				err = checkFormat(current, argTypes)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		values, err := evalArgs(rt, args)
+		if err != nil {
+			return nil, err
+		}
+
+		text := strings.Builder{}
+		next := 0
+		for _, it := range current {
+			if it.spec == nil {
+				text.WriteString(it.text)
+				continue
+			}
+
+			part, err := it.spec.format(rt, argTypes[next], values[next], defaults[next])
+			if err != nil {
+				return nil, err
+			}
+			text.WriteString(part)
+			next++
+		}
+
+		if expr.Print {
+			if _, err := rt.StdOut.Write([]byte(text.String())); err != nil {
+				return nil, fmt.Errorf("printf: %w", err)
+			}
+			return nil, nil
+		}
+		return text.String(), nil
+	}
+
+	if expr.Print {
+		return eval, types.Unit(), nil
+	}
+	return eval, typeStr, nil
+}
+
+func parseFormat(text string) (out []formatPiece, err error) {
+	chunk := strings.Builder{}
+	for pos := 0; pos < len(text); pos++ {
+		if text[pos] != '%' {
+			chunk.WriteByte(text[pos])
+			continue
+		}
+
+		start := pos
+		if pos++; pos < len(text) && text[pos] == '%' {
+			chunk.WriteByte('%')
+			continue
+		}
+
+		spec := &formatSpec{precision: -1}
+	flags:
+		for ; pos < len(text); pos++ {
+			switch text[pos] {
+			case '-':
+				spec.left = true
+			case '^':
+				spec.center = true
+			case '0':
+				spec.zero = true
+			case '+':
+				spec.plus = true
+			case '#':
+				spec.prefix = true
+			default:
+				break flags
+			}
+		}
+
+		var tooLarge bool
+		spec.width, pos, tooLarge = parseFormatNumber(text, pos)
+		if pos < len(text) && text[pos] == '.' {
+			var precisionTooLarge bool
+			spec.precision, pos, precisionTooLarge = parseFormatNumber(text, pos+1)
+			tooLarge = tooLarge || precisionTooLarge
+		}
+
+		if tooLarge {
+			return nil, errorf(codeInvalidFormat, "width and precision in `%s` cannot exceed %d", text[start:pos], formatMaxWidth)
+		}
+
+		if pos >= len(text) {
+			return nil, errorf(codeInvalidFormat, "incomplete format specifier `%s`", text[start:])
+		}
+
+		spec.verb = text[pos]
+		if !strings.ContainsRune("vsdxXobfegc", rune(spec.verb)) {
+			chr, _ := utf8.DecodeRuneInString(text[pos:])
+			return nil, errorf(codeInvalidFormat, "invalid format verb `%c` in `%s`", chr, text[start:pos+utf8.RuneLen(chr)])
+		}
+
+		if chunk.Len() > 0 {
+			out = append(out, formatPiece{text: chunk.String()})
+			chunk.Reset()
+		}
+		out = append(out, formatPiece{text: text[start : pos+1], spec: spec})
+	}
+
+	if chunk.Len() > 0 {
+		out = append(out, formatPiece{text: chunk.String()})
+	}
+	return out, nil
+}
+
+// Parses the decimal number at pos, returning the position after it. The
+// digits are consumed even if the number exceeds formatMaxWidth.
+func parseFormatNumber(text string, pos int) (value, next int, tooLarge bool) {
+	for ; pos < len(text) && '0' <= text[pos] && text[pos] <= '9'; pos++ {
+		if value = value*10 + int(text[pos]-'0'); value > formatMaxWidth {
+			value, tooLarge = formatMaxWidth, true
+		}
+	}
+	return value, pos, tooLarge
+}
+
+// Checks the format specifiers against the argument types.
+func checkFormat(pieces []formatPiece, args []Type) error {
+	count := 0
+	for _, it := range pieces {
+		if it.spec == nil {
+			continue
+		}
+
+		if count >= len(args) {
+			count++
+			continue
+		}
+
+		typ := args[count]
+		count++
+
+		spec := it.spec
+		scalar, _ := typ.Def().(TypeScalar)
+		var valid bool
+		switch spec.verb {
+		case 'v':
+			valid = true
+		case 's':
+			valid = scalar.kind == TypeScalarString || scalar.kind == TypeScalarChar
+		case 'd', 'o', 'b':
+			valid = typ.isInteger()
+		case 'x', 'X':
+			valid = typ.isInteger() || scalar.kind == TypeScalarBytes
+		case 'f', 'e', 'g':
+			valid = scalar.kind == TypeScalarFloat
+		case 'c':
+			valid = scalar.kind == TypeScalarChar
+		}
+
+		if !valid {
+			return errorf(codeInvalidFormat, "format `%s` does not accept `%s` for argument %d", it.text, typ, count)
+		}
+
+		if spec.precision >= 0 && !strings.ContainsRune("sfeg", rune(spec.verb)) {
+			return errorf(codeInvalidFormat, "format `%s` does not accept a precision", it.text)
+		}
+	}
+
+	if count != len(args) {
+		return errorf(codeInvalidFormat, "format has %d specifiers, but %d arguments were given", count, len(args))
+	}
+	return nil
+}
+
+func (spec *formatSpec) format(rt *Runtime, typ Type, val any, defaultFormat formatFunc) (string, error) {
+	var (
+		sign string
+		text string
+	)
+
+	switch spec.verb {
+	case 'v':
+		out, err := defaultFormat(rt, val)
+		if err != nil {
+			return "", err
+		}
+		text = out
+
+	case 's', 'c':
+		if chr, ok := val.(CharValue); ok {
+			text = chr.String()
+		} else {
+			text = val.(string)
+		}
+		if spec.precision >= 0 && utf8.RuneCountInString(text) > spec.precision {
+			text = string([]rune(text)[:spec.precision])
+		}
+
+	case 'd', 'x', 'X', 'o', 'b':
+		if bytes, ok := val.(BytesValue); ok {
+			text = fmt.Sprintf("%"+string(spec.verb), string(bytes))
+			break
+		}
+
+		num := integerToBig(typ, val)
+		if num.Sign() < 0 {
+			sign = "-"
+			num = new(big.Int).Neg(num)
+		} else if spec.plus {
+			sign = "+"
+		}
+
+		radix := map[byte]int{'d': 10, 'x': 16, 'X': 16, 'o': 8, 'b': 2}[spec.verb]
+		text = num.Text(radix)
+		if spec.verb == 'X' {
+			text = strings.ToUpper(text)
+		}
+		if spec.prefix && radix != 10 {
+			sign += map[byte]string{'x': "0x", 'X': "0x", 'o': "0o", 'b': "0b"}[spec.verb]
+		}
+
+	case 'f', 'e', 'g':
+		num := val.(float64)
+		text = strconv.FormatFloat(num, spec.verb, spec.precision, 64)
+		if strings.HasPrefix(text, "-") {
+			sign, text = "-", text[1:]
+		} else if spec.plus {
+			sign = "+"
+		}
+	}
+
+	return spec.pad(sign, text), nil
+}
+
+// Pads the text to the specifier width. Zero padding goes after the sign.
+func (spec *formatSpec) pad(sign, text string) string {
+	size := utf8.RuneCountInString(sign) + utf8.RuneCountInString(text)
+	if size >= spec.width {
+		return sign + text
+	}
+
+	fill := spec.width - size
+	switch {
+	case spec.zero && !spec.left && !spec.center && spec.verb != 'v' && spec.verb != 's' && spec.verb != 'c':
+		return sign + strings.Repeat("0", fill) + text
+	case spec.left:
+		return sign + text + strings.Repeat(" ", fill)
+	case spec.center:
+		return strings.Repeat(" ", fill/2) + sign + text + strings.Repeat(" ", fill-fill/2)
+	default:
+		return strings.Repeat(" ", fill) + sign + text
+	}
+}
@@ -7,13 +7,24 @@ import (
 )
 
 type Program struct {
+	// Diagnostics for the program. Only entries with error severity
+	// prevent it from running.
 	Errors base.ErrorSet
 
+	// Enable warnings for declarations shadowing an outer one and for
+	// variables that are never used.
+	WarnShadow bool
+	WarnUnused bool
+
+	// Project root directory, used to locate the source file for modules.
+	Root string
+
 	types TypeSet
 
 	codeSync sync.Mutex
-	codeList []Expr
+	modules  map[string]*Module
 
+	// frame for the top-level declarations of all modules
 	scope Scope
 }
 
@@ -22,12 +33,11 @@ func (program *Program) Types() *TypeSet {
 	return &program.types
 }
 
+// Appends code to the main module.
 func (program *Program) Append(code ...Expr) {
-	program.codeSync.Lock()
-	defer program.codeSync.Unlock()
-	program.codeList = append(program.codeList, code...)
+	program.Main().Append(code...)
 }
 
 func (program *Program) HasErrors() bool {
-	return program.Errors.Len() > 0
+	return program.Errors.HasErrors()
 }
@@ -0,0 +1,96 @@
+package code
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Creates a record value with the fields given in declaration order.
+//
+// For a generic record declaration, the type arguments are inferred from
+// the field values.
+type Record struct {
+	Type   Type
+	Fields []Expr
+}
+
+func (expr Record) IsExpr() {}
+
+func (expr Record) String() string {
+	out := strings.Builder{}
+	out.WriteString("Record(")
+	out.WriteString(expr.Type.String())
+	for _, it := range expr.Fields {
+		out.WriteString(", ")
+		out.WriteString(it.String())
+	}
+	out.WriteString(")")
+	return out.String()
+}
+
+type Field struct {
+	Value Expr
+	Name  Id
+}
+
+func (expr Field) IsExpr() {}
+
+func (expr Field) String() string {
+	return fmt.Sprintf("Field(%s.%s)", expr.Value, expr.Name)
+}
+
+func compileRecord(scope *Scope, expr Record) (eval EvalFunc, typ Type, err error) {
+	unifier := scope.Types().newUnifier()
+
+	typ = unifier.instantiate(scope.Type(expr.Type))
+	rec, ok := typ.Def().(TypeRecord)
+	if !ok {
+		return nil, typ, errorf(codeInvalidRecord, "`%s` is not a record type", typ)
+	}
+
+	fields := rec.Fields()
+	params := make([]Type, len(fields))
+	for n, it := range fields {
+		params[n] = it.Type
+	}
+
+	args, err := compileArgs(scope, expr.Fields, params, unifier)
+	if err != nil {
+		return nil, typ, fmt.Errorf("record `%s`: %w", rec.Name(), err)
+	}
+
+	if typ = unifier.resolve(typ); typ.HasVars() {
+		return nil, typ, errorf(codeCannotInfer, "cannot infer type arguments for record `%s`", rec.Name())
+	}
+
+	eval = func(rt *Runtime) (out any, err error) {
+		return evalArgs(rt, args)
+	}
+	return eval, typ, nil
+}
+
+func compileField(scope *Scope, expr Field) (eval EvalFunc, typ Type, err error) {
+	value, valueType, err := compileExpr(scope, expr.Value)
+	if err != nil {
+		return nil, typ, err
+	}
+
+	rec, ok := valueType.Def().(TypeRecord)
+	if !ok {
+		return nil, typ, errorf(codeInvalidRecord, "cannot access field `%s` of non-record type `%s`", expr.Name, valueType)
+	}
+
+	index := rec.FieldIndex(expr.Name)
+	if index < 0 {
+		return nil, typ, errorf(codeInvalidRecord, "record `%s` has no field `%s`", valueType, expr.Name)
+	}
+
+	eval = func(rt *Runtime) (out any, err error) {
+		val, err := value(rt)
+		if err != nil {
+			return nil, err
+		}
+		return val.([]any)[index], nil
+	}
+	return eval, rec.Fields()[index].Type, nil
+}
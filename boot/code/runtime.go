@@ -1,6 +1,7 @@
 package code
 
 import (
+	"bufio"
 	"io"
 	"sync/atomic"
 )
@@ -8,31 +9,72 @@ import (
 type Runtime struct {
 	StdErr io.Writer
 	StdOut io.Writer
+	StdIn  io.Reader
+
+	stdin *bufio.Reader
 
 	frameId atomic.Uint64
-	frames  []stackFrame
+	frames  []*stackFrame
 }
 
 func (rt *Runtime) GetVar(id VarId) any {
-	last := len(rt.frames) - 1
-	return rt.frames[last-int(id.frame)].vars[id.index]
+	return rt.frameOf(id).vars[id.index]
 }
 
 func (rt *Runtime) SetVar(id VarId, val any) {
-	last := len(rt.frames) - 1
-	rt.frames[last-int(id.frame)].vars[id.index] = val
+	rt.frameOf(id).vars[id.index] = val
+}
+
+func (rt *Runtime) frameOf(id VarId) *stackFrame {
+	frame := rt.frames[len(rt.frames)-1]
+	for n := uint32(0); n < id.frame; n++ {
+		frame = frame.parent
+	}
+	return frame
+}
+
+func (rt *Runtime) topFrame() *stackFrame {
+	if len(rt.frames) == 0 {
+		return nil
+	}
+	return rt.frames[len(rt.frames)-1]
+}
+
+// Returns the frame for the top-level program scope.
+func (rt *Runtime) rootFrame() *stackFrame {
+	if len(rt.frames) == 0 {
+		return nil
+	}
+	return rt.frames[0]
+}
+
+// Evaluates code compiled for the program scope from any point in the
+// evaluation, with the root frame as the top frame.
+func (rt *Runtime) evalAtRoot(eval EvalFunc) (out any, err error) {
+	rt.frames = append(rt.frames, rt.rootFrame())
+	defer func() {
+		rt.frames = rt.frames[:len(rt.frames)-1]
+	}()
+	return eval(rt)
 }
 
 func (rt *Runtime) InitScope(scope *Scope) (cleanFn func()) {
+	return rt.enterScope(scope, rt.topFrame())
+}
+
+// Pushes a new frame for the scope, using parent as the frame for the
+// enclosing lexical scope.
+func (rt *Runtime) enterScope(scope *Scope, parent *stackFrame) (cleanFn func()) {
 	runId := rt.frameId.Add(1)
-	frame := stackFrame{
-		runId: runId,
-		vars:  make([]any, scope.varCount),
+	frame := &stackFrame{
+		runId:  runId,
+		parent: parent,
+		vars:   make([]any, scope.varCount),
 	}
 
 	rt.frames = append(rt.frames, frame)
 	return func() {
-		last := &rt.frames[len(rt.frames)-1]
+		last := rt.frames[len(rt.frames)-1]
 		if last.runId != runId {
 			panic("cleaning up invalid frame in the stack")
 		}
@@ -40,7 +82,41 @@ func (rt *Runtime) InitScope(scope *Scope) (cleanFn func()) {
 	}
 }
 
+func (rt *Runtime) call(code *funcCode, env *stackFrame, args []any) (out any, err error) {
+	cleanup := rt.enterScope(code.scope, env)
+	defer cleanup()
+
+	frame := rt.topFrame()
+	frame.fn = code
+	copy(frame.vars, args)
+	return code.eval(rt)
+}
+
+// Returns the number of active function calls.
+func (rt *Runtime) callDepth() (depth int) {
+	for _, it := range rt.frames {
+		if it.fn != nil {
+			depth++
+		}
+	}
+	return depth
+}
+
+// Calls a function value, either a Bit function or a native.
+func (rt *Runtime) callValue(fn any, args []any) (out any, err error) {
+	if native, ok := fn.(*Native); ok {
+		return native.call(rt, args)
+	}
+
+	value := fn.(*FuncValue)
+	return rt.call(value.code, value.env, args)
+}
+
 type stackFrame struct {
-	runId uint64
-	vars  []any
+	runId  uint64
+	parent *stackFrame
+	vars   []any
+
+	// function for the frame, nil for inner scopes
+	fn *funcCode
 }
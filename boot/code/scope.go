@@ -3,7 +3,11 @@ package code
 import (
 	"fmt"
 	"math"
+	"sort"
+	"strings"
 	"sync"
+
+	"axlab.dev/bit/base"
 )
 
 type VarId struct {
@@ -12,12 +16,43 @@ type VarId struct {
 }
 
 type Scope struct {
-	root   *Scope
-	parent *Scope
+	root    *Scope
+	parent  *Scope
+	program *Program
+
+	// module for a module top-level scope
+	module *Module
+
+	// scope allocating the frame variables, if not the scope itself. Module
+	// scopes share the program frame, so their declarations are accessible
+	// from any module.
+	frame *Scope
+
+	typeVars []Type
+	typeArgs []Type
+
+	fn *funcCode
+
+	// disables warnings for the scope and its children, used for the
+	// instances of generic functions after the first
+	noWarn bool
+
+	// top-level declarations, for module scopes only
+	decls map[Expr]*topDecl
 
 	varSync  sync.Mutex
 	varCount uint32
-	varMap   map[Id]uint32
+	varMap   map[Id]*scopeVar
+}
+
+type scopeVar struct {
+	index  uint32
+	typ    Type
+	span   base.Span
+	fn     *funcDef
+	global *globalDef
+	used   bool
+	pub    bool
 }
 
 func (scope *Scope) NewChild() *Scope {
@@ -35,43 +70,190 @@ func (scope *Scope) getRoot() *Scope {
 	return scope
 }
 
+func (scope *Scope) Types() *TypeSet {
+	return scope.getRoot().program.Types()
+}
+
+// Returns the given type with all type arguments in scope applied.
+//
+// This is used when compiling the instance of a generic function, where
+// the declared types in the code refer to the generic type parameters.
+func (scope *Scope) Type(typ Type) Type {
+	for current := scope; current != nil; current = current.parent {
+		if len(current.typeVars) > 0 {
+			typ = current.Types().Substitute(typ, current.typeVars, current.typeArgs)
+		}
+	}
+	return typ
+}
+
+// Returns the function enclosing the scope, if any.
+func (scope *Scope) function() *funcCode {
+	for current := scope; current != nil; current = current.parent {
+		if current.fn != nil {
+			return current.fn
+		}
+	}
+	return nil
+}
+
 func (scope *Scope) Declare(v Var) (out VarId, err error) {
+	return scope.declare(v, base.Span{}, nil)
+}
+
+// Declares a variable at the given location. Declaring the same name twice
+// in a scope is an error, while declaring a name from an outer scope is a
+// shadowing warning, if enabled.
+func (scope *Scope) declare(v Var, span base.Span, fn *funcDef) (out VarId, err error) {
+	if out, err = scope.insertVar(v, span, fn); err != nil {
+		return out, err
+	}
+
+	if scope.parent != nil && scope.getRoot().program.WarnShadow && !isIgnored(v.Name) {
+		if _, outer, err := scope.parent.find(v.Name); err == nil {
+			scope.warn(&base.Diagnostic{
+				Severity: base.SeverityWarning,
+				Code:     codeShadowed,
+				Message:  fmt.Sprintf("`%s` shadows an outer declaration", v.Name),
+				Span:     span,
+				Related:  relatedAt(outer.span, "shadowed declaration"),
+			})
+		}
+	}
+	return out, nil
+}
+
+// Adds the variable to the scope, unless the name is already declared in
+// it. The check and the insert are done under the same lock, so only one
+// of concurrent declarations for a name succeeds.
+func (scope *Scope) insertVar(v Var, span base.Span, fn *funcDef) (out VarId, err error) {
 	scope.varSync.Lock()
 	defer scope.varSync.Unlock()
 
-	if scope.varCount == math.MaxUint32 {
-		return out, fmt.Errorf("variable count overflow in scope")
+	if first, found := scope.varMap[v.Name]; found {
+		return out, &base.Diagnostic{
+			Code:    codeRedeclared,
+			Message: fmt.Sprintf("`%s` is already declared", v.Name),
+			Related: relatedAt(first.span, "first declared here"),
+		}
 	}
 
-	index := scope.varCount
-	scope.varCount++
+	index, err := scope.allocVar()
+	if err != nil {
+		return out, err
+	}
 
 	if scope.varMap == nil {
-		scope.varMap = make(map[Id]uint32)
+		scope.varMap = make(map[Id]*scopeVar)
 	}
 
-	scope.varMap[v.Name] = index
+	scope.varMap[v.Name] = &scopeVar{index: index, typ: v.Type, span: span, fn: fn}
 	out = VarId{frame: 0, index: index}
 	return out, nil
 }
 
+// Allocates a variable in the frame for the scope. The scope lock must be
+// held, which also covers the frame when the scope owns it.
+func (scope *Scope) allocVar() (index uint32, err error) {
+	owner := scope
+	if scope.frame != nil && scope.frame != scope {
+		owner = scope.frame
+		owner.varSync.Lock()
+		defer owner.varSync.Unlock()
+	}
+
+	if owner.varCount == math.MaxUint32 {
+		return 0, errorf(codeTooManyVars, "variable count overflow in scope")
+	}
+
+	index = owner.varCount
+	owner.varCount++
+	return index, nil
+}
+
 func (scope *Scope) Resolve(v Var) (out VarId, err error) {
+	out, _, err = scope.lookup(v.Name)
+	return out, err
+}
+
+// Resolves the name, marking the variable as used.
+func (scope *Scope) lookup(name Id) (out VarId, decl *scopeVar, err error) {
+	if out, decl, err = scope.find(name); err == nil {
+		decl.used = true
+	}
+	return out, decl, err
+}
+
+func (scope *Scope) find(name Id) (out VarId, decl *scopeVar, err error) {
 	current, frame := scope, uint32(0)
 	for current != nil {
-		if index, found := current.tryResolve(v); found {
-			out = VarId{frame: frame, index: index}
-			return out, nil
+		if decl, found := current.tryResolve(name); found {
+			out = VarId{frame: frame, index: decl.index}
+			return out, decl, nil
 		}
 		current = current.parent
 		frame++
 	}
 
-	return out, fmt.Errorf("variable `%s` not in the scope", v.Name)
+	return out, nil, errorf(codeUndefined, "variable `%s` not in the scope", name)
 }
 
-func (scope *Scope) tryResolve(v Var) (index uint32, found bool) {
+func (scope *Scope) tryResolve(name Id) (decl *scopeVar, found bool) {
 	scope.varSync.Lock()
 	defer scope.varSync.Unlock()
-	index, found = scope.varMap[v.Name]
+	decl, found = scope.varMap[name]
 	return
 }
+
+// Adds a warning for the program, unless disabled for the scope.
+func (scope *Scope) warn(diag *base.Diagnostic) {
+	for current := scope; current != nil; current = current.parent {
+		if current.noWarn {
+			return
+		}
+	}
+	scope.getRoot().program.Errors.Add(diag)
+}
+
+// Warns about the variables in the scope that were never used, if enabled.
+// This must be called once the code for the scope is compiled.
+func (scope *Scope) checkUnused() {
+	if !scope.getRoot().program.WarnUnused {
+		return
+	}
+
+	scope.varSync.Lock()
+	var unused []Id
+	for name, it := range scope.varMap {
+		if !it.used && !isIgnored(name) {
+			unused = append(unused, name)
+		}
+	}
+	sort.Slice(unused, func(a, b int) bool {
+		return scope.varMap[unused[a]].index < scope.varMap[unused[b]].index
+	})
+	decls := make([]*scopeVar, len(unused))
+	for n, name := range unused {
+		decls[n] = scope.varMap[name]
+	}
+	scope.varSync.Unlock()
+
+	for n, decl := range decls {
+		kind := "variable"
+		if decl.fn != nil {
+			kind = "function"
+		}
+		scope.warn(&base.Diagnostic{
+			Severity: base.SeverityWarning,
+			Code:     codeUnused,
+			Message:  fmt.Sprintf("unused %s `%s`", kind, unused[n]),
+			Span:     decl.span,
+		})
+	}
+}
+
+// Names starting with an underscore and `self` are not checked for
+// shadowing or use.
+func isIgnored(name Id) bool {
+	return name == "self" || strings.HasPrefix(string(name), "_")
+}
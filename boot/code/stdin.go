@@ -0,0 +1,86 @@
+package code
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Returns the buffered reader for the runtime input. A nil StdIn is read
+// as empty.
+func (rt *Runtime) input() *bufio.Reader {
+	if rt.stdin == nil {
+		input := rt.StdIn
+		if input == nil {
+			input = strings.NewReader("")
+		}
+		rt.stdin = bufio.NewReader(input)
+	}
+	return rt.stdin
+}
+
+// Reads the next line from the input without the line break, returning
+// false at the end of the input. The last line may not end with a line
+// break.
+func (rt *Runtime) ReadLine() (line string, ok bool, err error) {
+	line, err = rt.input().ReadString('\n')
+	if err == io.EOF {
+		if line == "" {
+			return "", false, nil
+		}
+	} else if err != nil {
+		return "", false, fmt.Errorf("reading input: %w", err)
+	}
+
+	line = strings.TrimSuffix(line, "\n")
+	line = strings.TrimSuffix(line, "\r")
+	return line, true, nil
+}
+
+// Builtin functions for reading the runtime input.
+//
+// The end of the input is a `None` from `read_line`, while I/O errors fail
+// the evaluation. The `read_all` function instead returns a Result, with an
+// `Err` for I/O errors and input that is not valid UTF-8. The function
+// returned by `lines` can be iterated over with a For loop.
+func stdinFuncs(set *TypeSet) []*Native {
+	typeStr := set.Scalar(TypeScalarString)
+	readLine := &Native{
+		Name: "read_line",
+		Type: set.Func(set.Option(typeStr)),
+		Eval: func(rt *Runtime, args []any) (out any, err error) {
+			line, ok, err := rt.ReadLine()
+			if err != nil {
+				return nil, err
+			}
+			return optionValue(line, ok), nil
+		},
+	}
+
+	return []*Native{
+		readLine,
+		{
+			Name: "read_all",
+			Type: set.Func(set.Result(typeStr, set.Error())),
+			Eval: func(rt *Runtime, args []any) (out any, err error) {
+				data, err := io.ReadAll(rt.input())
+				if err != nil {
+					return nil, fmt.Errorf("reading input: %w", err)
+				}
+				text := string(data)
+				if err := checkUTF8(text); err != nil {
+					return nil, err
+				}
+				return text, nil
+			},
+		},
+		{
+			Name: "lines",
+			Type: set.Func(readLine.Type),
+			Eval: func(rt *Runtime, args []any) (out any, err error) {
+				return readLine, nil
+			},
+		},
+	}
+}
@@ -1,6 +1,9 @@
 package code
 
-import "fmt"
+import (
+	"fmt"
+	"unicode/utf8"
+)
 
 type Str struct {
 	Value string
@@ -19,3 +22,28 @@ func (typeStr) IsType() {}
 func (typeStr) String() string {
 	return "Str"
 }
+
+// Builtin methods for strings. The `len` of a string is its number of chars.
+func stringMethods(typ Type) []*Native {
+	types := typ.Set()
+	return []*Native{
+		{
+			Name: "len",
+			Type: types.Func(types.Scalar(TypeScalarInt), typ),
+			Eval: func(rt *Runtime, args []any) (out any, err error) {
+				return int64(utf8.RuneCountInString(args[0].(string))), nil
+			},
+		},
+		{
+			Name: "chars",
+			Type: types.Func(types.List(types.Scalar(TypeScalarChar)), typ),
+			Eval: func(rt *Runtime, args []any) (out any, err error) {
+				list := &ListValue{Items: []any{}}
+				for _, chr := range args[0].(string) {
+					list.Items = append(list.Items, CharValue(chr))
+				}
+				return list, nil
+			},
+		},
+	}
+}
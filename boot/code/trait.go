@@ -0,0 +1,152 @@
+package code
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Calls a trait method, using the first argument as the receiver.
+//
+// Calls are dispatched statically when the receiver type is known, and
+// dynamically through the trait object otherwise.
+type TraitCall struct {
+	Trait  Type
+	Method Id
+	Args   []Expr
+}
+
+func (expr TraitCall) IsExpr() {}
+
+func (expr TraitCall) String() string {
+	out := strings.Builder{}
+	out.WriteString(fmt.Sprintf("TraitCall(%s.%s", expr.Trait, expr.Method))
+	for _, it := range expr.Args {
+		out.WriteString(", ")
+		out.WriteString(it.String())
+	}
+	out.WriteString(")")
+	return out.String()
+}
+
+// Runtime value for a trait object.
+type TraitObject struct {
+	Type  Type
+	Value any
+
+	vtable map[Id]*funcCode
+}
+
+func compileTraitCall(scope *Scope, expr TraitCall) (eval EvalFunc, typ Type, err error) {
+	traitType := scope.Type(expr.Trait)
+	trait, ok := traitType.Def().(TypeTrait)
+	if !ok {
+		return nil, typ, errorf(codeInvalidTrait, "`%s` is not a trait", expr.Trait)
+	}
+
+	sig, ok := trait.Method(expr.Method)
+	if !ok {
+		return nil, typ, errorf(codeInvalidTrait, "trait `%s` has no method `%s`", trait.Name(), expr.Method)
+	}
+
+	if len(expr.Args) == 0 {
+		return nil, typ, errorf(codeInvalidTrait, "call to `%s.%s` is missing the receiver", trait.Name(), expr.Method)
+	}
+
+	recv, recvType, err := compileExpr(scope, expr.Args[0])
+	if err != nil {
+		return nil, typ, err
+	}
+
+	return compileTraitMethod(scope, traitType, sig, recv, recvType, expr.Args[1:])
+}
+
+func compileTraitMethod(scope *Scope, traitType Type, sig TraitMethod, recv EvalFunc, recvType Type, rest []Expr) (eval EvalFunc, typ Type, err error) {
+	types := scope.Types()
+	trait := traitType.Def().(TypeTrait)
+	method := sig.Name
+
+	fn := types.Substitute(sig.Type, []Type{trait.Self()}, []Type{recvType}).Def().(TypeFunc)
+	args, err := compileArgs(scope, rest, fn.params[1:], nil)
+	if err != nil {
+		return nil, typ, fmt.Errorf("in call to `%s.%s`: %w", trait.Name(), method, err)
+	}
+	args = append([]EvalFunc{recv}, args...)
+
+	if recvType == traitType {
+		if !trait.IsObjectSafe(sig) {
+			return nil, typ, errorf(codeInvalidTrait, "method `%s.%s` cannot be called on a trait object", trait.Name(), method)
+		}
+
+		eval = func(rt *Runtime) (out any, err error) {
+			argValues, err := evalArgs(rt, args)
+			if err != nil {
+				return nil, err
+			}
+
+			obj := argValues[0].(TraitObject)
+			argValues[0] = obj.Value
+			return rt.call(obj.vtable[method], rt.rootFrame(), argValues)
+		}
+		return eval, fn.result, nil
+	}
+
+	if !types.Implements(recvType, traitType) {
+		return nil, typ, errorf(codeNotImplemented, "type `%s` does not implement `%s`", recvType, trait.Name())
+	}
+
+	if _, isVar := recvType.Def().(TypeVar); isVar {
+		eval = func(rt *Runtime) (out any, err error) {
+			unreachableGeneric(fmt.Sprintf("call to `%s.%s`", trait.Name(), method), recvType)
+			return nil, nil
+		}
+		return eval, fn.result, nil
+	}
+
+	methods, err := types.implMethods(traitType, recvType)
+	if err != nil {
+		return nil, typ, err
+	}
+
+	code := methods[method]
+	eval = func(rt *Runtime) (out any, err error) {
+		argValues, err := evalArgs(rt, args)
+		if err != nil {
+			return nil, err
+		}
+		return rt.call(code, rt.rootFrame(), argValues)
+	}
+	return eval, fn.result, nil
+}
+
+func isTraitObject(typ Type) bool {
+	_, ok := typ.Def().(TypeTrait)
+	return ok
+}
+
+// Boxes a value into a trait object, resolving its methods statically.
+func coerceTrait(scope *Scope, eval EvalFunc, from, to Type) (EvalFunc, error) {
+	types := scope.Types()
+	if !types.Implements(from, to) {
+		return nil, errorf(codeNotImplemented, "type `%s` does not implement `%s`", from, to)
+	}
+
+	if _, isVar := from.Def().(TypeVar); isVar {
+		return func(rt *Runtime) (out any, err error) {
+			unreachableGeneric(fmt.Sprintf("conversion to `%s`", to), from)
+			return nil, nil
+		}, nil
+	}
+
+	vtable, err := types.implMethods(to, from)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(rt *Runtime) (out any, err error) {
+		val, err := eval(rt)
+		if err != nil {
+			return nil, err
+		}
+		return TraitObject{Type: from, Value: val, vtable: vtable}, nil
+	}, nil
+}
@@ -0,0 +1,73 @@
+package code
+
+import "fmt"
+
+// Unwraps an Option or Result value, i.e. the postfix `value?` operator.
+//
+// On `None` or `Err`, the enclosing function returns early with the same
+// value, so the function must return an Option or a Result with the same
+// error type.
+type Try struct {
+	Value Expr
+}
+
+func (expr Try) IsExpr() {}
+
+func (expr Try) String() string {
+	return fmt.Sprintf("Try(%s)", expr.Value)
+}
+
+// Used to unwind the evaluation for an early return from a function.
+type funcReturn struct {
+	value any
+}
+
+func (ret *funcReturn) Error() string {
+	return "return outside of a function"
+}
+
+func compileTry(scope *Scope, expr Try) (eval EvalFunc, typ Type, err error) {
+	value, valueType, err := compileExpr(scope, expr.Value)
+	if err != nil {
+		return nil, typ, err
+	}
+
+	code := scope.function()
+	if code == nil {
+		return nil, typ, errorf(codeInvalidTry, "`?` can only be used inside a function")
+	}
+	result := code.typ.Def().(TypeFunc).result
+
+	var success int
+	switch {
+	case valueType.isOption():
+		if !result.isOption() {
+			return nil, typ, errorf(codeInvalidTry, "`?` on `%s` requires function `%s` to return an Option, not `%s`", valueType, code.name, result)
+		}
+		success = optionSome
+	case valueType.isResult():
+		errType := valueType.Def().(TypeEnum).args[1]
+		if !result.isResult() {
+			return nil, typ, errorf(codeInvalidTry, "`?` on `%s` requires function `%s` to return a Result, not `%s`", valueType, code.name, result)
+		} else if want := result.Def().(TypeEnum).args[1]; want != errType {
+			return nil, typ, errorf(codeInvalidTry, "`?` on `%s` has error type `%s`, but function `%s` returns `%s`", valueType, errType, code.name, result)
+		}
+		success = resultOk
+	default:
+		return nil, typ, errorf(codeInvalidTry, "`?` requires an Option or Result value, got `%s`", valueType)
+	}
+
+	eval = func(rt *Runtime) (out any, err error) {
+		val, err := value(rt)
+		if err != nil {
+			return nil, err
+		}
+
+		enum := val.(EnumValue)
+		if enum.Variant != success {
+			return nil, &funcReturn{value: enum}
+		}
+		return enum.Value, nil
+	}
+	return eval, valueType.Def().(TypeEnum).args[0], nil
+}
@@ -0,0 +1,37 @@
+package code
+
+import "strings"
+
+type Tuple struct {
+	Items []Expr
+}
+
+func (expr Tuple) IsExpr() {}
+
+func (expr Tuple) String() string {
+	out := strings.Builder{}
+	out.WriteString("Tuple(")
+	for n, it := range expr.Items {
+		if n > 0 {
+			out.WriteString(", ")
+		}
+		out.WriteString(it.String())
+	}
+	out.WriteString(")")
+	return out.String()
+}
+
+func compileTuple(scope *Scope, expr Tuple) (eval EvalFunc, typ Type, err error) {
+	items := make([]EvalFunc, len(expr.Items))
+	types := make([]Type, len(expr.Items))
+	for n, it := range expr.Items {
+		if items[n], types[n], err = compileExpr(scope, it); err != nil {
+			return nil, typ, err
+		}
+	}
+
+	eval = func(rt *Runtime) (out any, err error) {
+		return evalArgs(rt, items)
+	}
+	return eval, scope.Types().Tuple(types...), nil
+}
@@ -9,19 +9,34 @@ type Type struct {
 	data *typeData
 }
 
+func (typ Type) Valid() bool {
+	return typ.data != nil
+}
+
 func (typ Type) String() string {
+	if typ.data == nil {
+		return "Type(nil)"
+	}
 	return typ.Def().String()
 }
 
 type typeData struct {
 	set *TypeSet
 	def TypeDef
+
+	methodInit sync.Once
+	methodSync sync.Mutex
+	methodMap  map[Id][]*typeMethod
 }
 
 func (typ Type) Def() TypeDef {
 	return typ.data.def
 }
 
+func (typ Type) Set() *TypeSet {
+	return typ.data.set
+}
+
 type TypeDef interface {
 	TypeDef() TypeDef
 	String() string
@@ -37,6 +52,22 @@ type TypeSet struct {
 
 	tupleSync sync.Mutex
 	tupleMap  map[TypeKey]Type
+
+	funcSync sync.Mutex
+	funcMap  map[TypeKey]Type
+
+	listSync sync.Mutex
+	listMap  map[TypeKey]Type
+
+	mapSync sync.Mutex
+	mapMap  map[TypeKey]Type
+
+	varId atomic.Uint64
+
+	implSync sync.Mutex
+	implMap  map[*typeData][]*implDef
+
+	builtin typeBuiltins
 }
 
 func (set *TypeSet) Program() *Program {
@@ -47,6 +78,10 @@ func (set *TypeSet) GetKey(types ...Type) TypeKey {
 	return set.keys.Get(types, 0)
 }
 
+func (set *TypeSet) Unit() Type {
+	return set.Scalar(TypeScalarUnit)
+}
+
 func (set *TypeSet) Scalar(kind TypeScalarKind) Type {
 	set.scalarSync.Lock()
 	defer set.scalarSync.Unlock()
@@ -81,6 +116,25 @@ func (set *TypeSet) Tuple(types ...Type) Type {
 	return typ
 }
 
+func (set *TypeSet) Func(result Type, params ...Type) Type {
+	key := set.GetKey(append(append([]Type(nil), params...), result)...)
+
+	set.funcSync.Lock()
+	defer set.funcSync.Unlock()
+
+	typ, ok := set.funcMap[key]
+	if !ok {
+		list := key.Types()
+		typ = set.newType(TypeFunc{params: list[:len(list)-1], result: list[len(list)-1]})
+		if set.funcMap == nil {
+			set.funcMap = make(map[TypeKey]Type)
+		}
+		set.funcMap[key] = typ
+	}
+
+	return typ
+}
+
 func (set *TypeSet) newType(def TypeDef) Type {
 	data := &typeData{
 		set: set,
@@ -0,0 +1,103 @@
+package code
+
+import "sync"
+
+// Generic types declared by the language.
+type typeBuiltins struct {
+	init   sync.Once
+	option Type
+	result Type
+	error  Type
+	show   Type
+
+	funcsInit sync.Once
+	funcs     map[Id]*Native
+}
+
+func (set *TypeSet) builtins() *typeBuiltins {
+	out := &set.builtin
+	out.init.Do(func() {
+		typeT := set.Var("T")
+		out.option = set.Enum("Option", typeT)
+		out.option.Def().(TypeEnum).Define(
+			EnumVariant{Name: "None"},
+			EnumVariant{Name: "Some", Type: typeT},
+		)
+
+		typeT, typeE := set.Var("T"), set.Var("E")
+		out.result = set.Enum("Result", typeT, typeE)
+		out.result.Def().(TypeEnum).Define(
+			EnumVariant{Name: "Ok", Type: typeT},
+			EnumVariant{Name: "Err", Type: typeE},
+		)
+
+		out.error = set.Record("Error")
+		out.error.Def().(TypeRecord).Define(
+			RecordField{Name: "message", Type: set.Scalar(TypeScalarString)},
+		)
+
+		out.show = set.Trait("Show")
+		self := out.show.Def().(TypeTrait).Self()
+		out.show.Def().(TypeTrait).Define(
+			TraitMethod{Name: "show", Type: set.Func(set.Scalar(TypeScalarString), self)},
+		)
+	})
+	return out
+}
+
+// Returns the generic `Option[T]` enum, with variants `None` and `Some(T)`.
+func (set *TypeSet) OptionDecl() Type {
+	return set.builtins().option
+}
+
+func (set *TypeSet) Option(typ Type) Type {
+	return set.builtins().option.Def().(TypeEnum).instance([]Type{typ})
+}
+
+// Returns the generic `Result[T, E]` enum, with variants `Ok(T)` and
+// `Err(E)`.
+func (set *TypeSet) ResultDecl() Type {
+	return set.builtins().result
+}
+
+func (set *TypeSet) Result(typ, err Type) Type {
+	return set.builtins().result.Def().(TypeEnum).instance([]Type{typ, err})
+}
+
+// Returns the `Error` record used for errors from native functions. It has
+// a single `message` field.
+func (set *TypeSet) Error() Type {
+	return set.builtins().error
+}
+
+func (typ Type) isOption() bool {
+	enum, ok := typ.Def().(TypeEnum)
+	return ok && enum.Generic() == typ.Set().OptionDecl()
+}
+
+func (typ Type) isResult() bool {
+	enum, ok := typ.Def().(TypeEnum)
+	return ok && enum.Generic() == typ.Set().ResultDecl()
+}
+
+// Variant indexes for the builtin enums.
+const (
+	optionNone = 0
+	optionSome = 1
+	resultOk   = 0
+	resultErr  = 1
+)
+
+func optionValue(val any, ok bool) EnumValue {
+	if !ok {
+		return EnumValue{Variant: optionNone}
+	}
+	return EnumValue{Variant: optionSome, Value: val}
+}
+
+func resultValue(val any, err error) EnumValue {
+	if err != nil {
+		return EnumValue{Variant: resultErr, Value: []any{err.Error()}}
+	}
+	return EnumValue{Variant: resultOk, Value: val}
+}
@@ -0,0 +1,112 @@
+package code
+
+import "sync"
+
+type TypeEnum struct {
+	decl *enumDecl
+	args []Type
+}
+
+// Variants without a payload have an invalid Type.
+type EnumVariant struct {
+	Name Id
+	Type Type
+}
+
+type enumDecl struct {
+	typeDecl
+
+	variantSync sync.Mutex
+	variantDone bool
+	variants    []EnumVariant
+}
+
+// Declares a new nominal enum type with the given type parameters.
+//
+// As with records, the returned type is the generic declaration and its
+// variants must be provided by `Define`.
+func (set *TypeSet) Enum(name Id, params ...Type) Type {
+	decl := &enumDecl{}
+	decl.init(set, name, params)
+	return decl.get(params)
+}
+
+func (decl *enumDecl) get(args []Type) Type {
+	return decl.instance(args, func(args []Type) TypeDef {
+		return TypeEnum{decl: decl, args: args}
+	})
+}
+
+func (enum TypeEnum) TypeDef() TypeDef { return enum }
+
+func (enum TypeEnum) instance(args []Type) Type {
+	return enum.decl.get(args)
+}
+
+func (enum TypeEnum) Define(variants ...EnumVariant) error {
+	enum.decl.variantSync.Lock()
+	defer enum.decl.variantSync.Unlock()
+
+	if enum.decl.variantDone {
+		return errorf(codeInvalidTypeDef, "enum `%s` is already defined", enum.decl.name)
+	}
+
+	names := make(map[Id]bool)
+	for _, it := range variants {
+		if names[it.Name] {
+			return errorf(codeInvalidTypeDef, "enum `%s` has duplicated variant `%s`", enum.decl.name, it.Name)
+		}
+		names[it.Name] = true
+	}
+
+	enum.decl.variants = variants
+	enum.decl.variantDone = true
+	return nil
+}
+
+func (enum TypeEnum) Name() Id {
+	return enum.decl.name
+}
+
+func (enum TypeEnum) Args() []Type {
+	return enum.args
+}
+
+func (enum TypeEnum) Generic() Type {
+	return enum.decl.get(enum.decl.params)
+}
+
+// Returns the enum variants with the type arguments applied.
+func (enum TypeEnum) Variants() []EnumVariant {
+	enum.decl.variantSync.Lock()
+	variants := enum.decl.variants
+	enum.decl.variantSync.Unlock()
+
+	if len(enum.args) == 0 || enum.decl.isGeneric(enum.args) {
+		return variants
+	}
+
+	out := make([]EnumVariant, len(variants))
+	for n, it := range variants {
+		out[n] = EnumVariant{Name: it.Name}
+		if it.Type.Valid() {
+			out[n].Type = enum.decl.substitute(enum.args, it.Type)
+		}
+	}
+	return out
+}
+
+func (enum TypeEnum) VariantIndex(name Id) int {
+	enum.decl.variantSync.Lock()
+	defer enum.decl.variantSync.Unlock()
+	for n, it := range enum.decl.variants {
+		if it.Name == name {
+			return n
+		}
+	}
+	return -1
+}
+
+func (enum TypeEnum) String() string {
+	return enum.decl.String(enum.args)
+}
@@ -0,0 +1,32 @@
+package code
+
+import "strings"
+
+type TypeFunc struct {
+	params []Type
+	result Type
+}
+
+func (fn TypeFunc) TypeDef() TypeDef { return fn }
+
+func (fn TypeFunc) Params() []Type {
+	return fn.params
+}
+
+func (fn TypeFunc) Result() Type {
+	return fn.result
+}
+
+func (fn TypeFunc) String() string {
+	out := strings.Builder{}
+	out.WriteString("Func(")
+	for n, it := range fn.params {
+		if n > 0 {
+			out.WriteString(", ")
+		}
+		out.WriteString(it.String())
+	}
+	out.WriteString(") -> ")
+	out.WriteString(fn.result.String())
+	return out.String()
+}
@@ -0,0 +1,126 @@
+package code
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Common declaration data for nominal types with type parameters.
+//
+// Every instantiation of a declaration is interned by its arguments, so
+// applying the same arguments always results in the same Type.
+type typeDecl struct {
+	set    *TypeSet
+	name   Id
+	params []Type
+
+	instSync sync.Mutex
+	instMap  map[TypeKey]Type
+}
+
+type typeGeneric interface {
+	TypeDef
+	Name() Id
+	Args() []Type
+	Generic() Type
+	instance(args []Type) Type
+}
+
+func (decl *typeDecl) init(set *TypeSet, name Id, params []Type) {
+	for _, it := range params {
+		if _, ok := it.Def().(TypeVar); !ok {
+			panic(fmt.Sprintf("type parameter for `%s` is not a type variable: %s", name, it))
+		}
+	}
+	decl.set = set
+	decl.name = name
+	decl.params = params
+}
+
+func (decl *typeDecl) instance(args []Type, newDef func(args []Type) TypeDef) Type {
+	set := decl.set
+	if len(args) != len(decl.params) {
+		panic(fmt.Sprintf("invalid argument count for `%s`", decl.name))
+	}
+
+	key := set.GetKey(args...)
+
+	decl.instSync.Lock()
+	defer decl.instSync.Unlock()
+
+	typ, ok := decl.instMap[key]
+	if !ok {
+		typ = set.newType(newDef(key.Types()))
+		if decl.instMap == nil {
+			decl.instMap = make(map[TypeKey]Type)
+		}
+		decl.instMap[key] = typ
+	}
+
+	return typ
+}
+
+func (decl *typeDecl) isGeneric(args []Type) bool {
+	if len(args) == 0 {
+		return false
+	}
+	for n, it := range args {
+		if it != decl.params[n] {
+			return false
+		}
+	}
+	return true
+}
+
+func (decl *typeDecl) substitute(args []Type, typ Type) Type {
+	return decl.set.Substitute(typ, decl.params, args)
+}
+
+func (decl *typeDecl) String(args []Type) string {
+	if len(args) == 0 {
+		return string(decl.name)
+	}
+
+	out := strings.Builder{}
+	out.WriteString(string(decl.name))
+	out.WriteString("[")
+	for n, it := range args {
+		if n > 0 {
+			out.WriteString(", ")
+		}
+		out.WriteString(it.String())
+	}
+	out.WriteString("]")
+	return out.String()
+}
+
+// Returns the instance of a generic declaration for the given arguments.
+//
+// Instances are interned, so `Pair[Int, String]` is the same Type no matter
+// how many times it is instantiated.
+func (set *TypeSet) Instance(typ Type, args ...Type) (Type, error) {
+	generic, ok := typ.Def().(typeGeneric)
+	if !ok {
+		return Type{}, errorf(codeTypeArgs, "type `%s` is not generic", typ)
+	}
+
+	decl := generic.Generic().Def().(typeGeneric)
+	if want := len(decl.Args()); want != len(args) {
+		return Type{}, errorf(codeTypeArgs, "type `%s` expects %d type arguments, got %d", decl.Name(), want, len(args))
+	}
+
+	return generic.instance(args), nil
+}
+
+// Returns true if the type is a generic declaration that has not been
+// instantiated (i.e. its arguments are its own type parameters).
+func (typ Type) IsGeneric() bool {
+	switch def := typ.Def().(type) {
+	case TypeRecord:
+		return def.decl.isGeneric(def.args)
+	case TypeEnum:
+		return def.decl.isGeneric(def.args)
+	}
+	return false
+}
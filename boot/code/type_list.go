@@ -0,0 +1,33 @@
+package code
+
+type TypeList struct {
+	elem Type
+}
+
+func (set *TypeSet) List(elem Type) Type {
+	key := set.GetKey(elem)
+
+	set.listSync.Lock()
+	defer set.listSync.Unlock()
+
+	typ, ok := set.listMap[key]
+	if !ok {
+		typ = set.newType(TypeList{elem: key.Types()[0]})
+		if set.listMap == nil {
+			set.listMap = make(map[TypeKey]Type)
+		}
+		set.listMap[key] = typ
+	}
+
+	return typ
+}
+
+func (list TypeList) TypeDef() TypeDef { return list }
+
+func (list TypeList) Elem() Type {
+	return list.elem
+}
+
+func (list TypeList) String() string {
+	return "List[" + list.elem.String() + "]"
+}
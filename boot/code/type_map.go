@@ -0,0 +1,125 @@
+package code
+
+import "fmt"
+
+type TypeMap struct {
+	key Type
+	val Type
+}
+
+func (set *TypeSet) Map(key, val Type) Type {
+	k := set.GetKey(key, val)
+
+	set.mapSync.Lock()
+	defer set.mapSync.Unlock()
+
+	typ, ok := set.mapMap[k]
+	if !ok {
+		list := k.Types()
+		typ = set.newType(TypeMap{key: list[0], val: list[1]})
+		if set.mapMap == nil {
+			set.mapMap = make(map[TypeKey]Type)
+		}
+		set.mapMap[k] = typ
+	}
+
+	return typ
+}
+
+func (m TypeMap) TypeDef() TypeDef { return m }
+
+func (m TypeMap) Key() Type {
+	return m.key
+}
+
+func (m TypeMap) Value() Type {
+	return m.val
+}
+
+func (m TypeMap) String() string {
+	return fmt.Sprintf("Map[%s, %s]", m.key, m.val)
+}
+
+// Returns true if values of the type can be used as map keys.
+//
+// Keys are compared and hashed structurally, so only immutable values with
+// a structural equality are hashable. Floats are not, since NaN is not
+// equal to itself and `-0.0` equals `0.0` with a different representation.
+// Type variables are assumed hashable, since they are checked when a
+// generic function is instantiated.
+func (typ Type) Hashable() bool {
+	return typ.hashable(make(map[Type]bool))
+}
+
+func (typ Type) hashable(visited map[Type]bool) bool {
+	if visited[typ] {
+		return true
+	}
+	visited[typ] = true
+
+	switch def := typ.Def().(type) {
+	case TypeScalar:
+		return def.Kind() != TypeScalarFloat
+	case TypeVar:
+		return true
+	case TypeTuple:
+		for _, it := range def.types {
+			if !it.hashable(visited) {
+				return false
+			}
+		}
+		return true
+	case TypeRecord:
+		for _, it := range def.Fields() {
+			if !it.Type.hashable(visited) {
+				return false
+			}
+		}
+		return true
+	case TypeEnum:
+		for _, it := range def.Variants() {
+			if it.Type.Valid() && !it.Type.hashable(visited) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// Checks that all map types used by the type have hashable keys.
+func (typ Type) checkMapKeys() error {
+	var err error
+	typ.walk(func(it Type) {
+		if m, ok := it.Def().(TypeMap); ok && err == nil && !m.key.Hashable() {
+			err = errorf(codeInvalidMapKey, "type `%s` cannot be used as a map key", m.key)
+		}
+	})
+	return err
+}
+
+// Calls fn for the type and each of its structural components.
+func (typ Type) walk(fn func(Type)) {
+	fn(typ)
+	switch def := typ.Def().(type) {
+	case TypeTuple:
+		for _, it := range def.types {
+			it.walk(fn)
+		}
+	case TypeFunc:
+		for _, it := range def.params {
+			it.walk(fn)
+		}
+		def.result.walk(fn)
+	case TypeList:
+		def.elem.walk(fn)
+	case TypeMap:
+		def.key.walk(fn)
+		def.val.walk(fn)
+	case typeGeneric:
+		for _, it := range def.Args() {
+			it.walk(fn)
+		}
+	}
+}
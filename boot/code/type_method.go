@@ -0,0 +1,135 @@
+package code
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Entry in the method table of a type. Methods are either declared by an
+// inherent Impl or builtin.
+//
+// Declared methods apply to the receiver type of their Impl, which may use
+// the generic parameters of the Impl (e.g. `impl[T] Pair[T, T]`). Builtin
+// methods apply to any receiver.
+type typeMethod struct {
+	def    *funcDef
+	native *Native
+
+	typ      Type
+	generics []Type
+}
+
+// Returns true if the method applies to the receiver type.
+func (method *typeMethod) matches(set *TypeSet, typ Type) bool {
+	if method.native != nil {
+		return true
+	}
+	unifier := set.newUnifier()
+	pattern := set.Substitute(method.typ, method.generics, unifier.fresh(method.generics))
+	return unifier.unify(pattern, typ) == nil
+}
+
+// Returns true if both methods apply to some receiver type.
+func (method *typeMethod) overlaps(set *TypeSet, other *typeMethod) bool {
+	if method.native != nil || other.native != nil {
+		return true
+	}
+	unifier := set.newUnifier()
+	a := set.Substitute(method.typ, method.generics, unifier.fresh(method.generics))
+	b := set.Substitute(other.typ, other.generics, unifier.fresh(other.generics))
+	return unifier.unify(a, b) == nil
+}
+
+// Methods for generic declarations are stored in the generic type, so the
+// methods for all instances are in the same table. Each method is matched
+// against the receiver type when looked up, so different instances can
+// declare methods with the same name.
+func (typ Type) methodOwner() Type {
+	if generic, ok := typ.Def().(typeGeneric); ok {
+		return generic.Generic()
+	}
+	return typ
+}
+
+func (typ Type) methodTable() *typeData {
+	data := typ.methodOwner().data
+	data.methodInit.Do(func() {
+		for _, it := range builtinMethods(typ.methodOwner()) {
+			if data.methodMap == nil {
+				data.methodMap = make(map[Id][]*typeMethod)
+			}
+			data.methodMap[it.Name] = []*typeMethod{{native: it}}
+		}
+	})
+	return data
+}
+
+// Adds a declared method for the receiver type of the method, failing if
+// a method with the same name applies to any of the same types.
+func (set *TypeSet) addMethod(name Id, method *typeMethod) error {
+	data := method.typ.methodTable()
+	data.methodSync.Lock()
+	defer data.methodSync.Unlock()
+
+	for _, it := range data.methodMap[name] {
+		if it.overlaps(set, method) {
+			return errorf(codeImplMethod, "duplicated method `%s` for `%s`", name, method.typ)
+		}
+	}
+
+	if data.methodMap == nil {
+		data.methodMap = make(map[Id][]*typeMethod)
+	}
+	data.methodMap[name] = append(data.methodMap[name], method)
+	return nil
+}
+
+// Returns the method with the given name that applies to the type.
+func (set *TypeSet) lookupMethod(typ Type, name Id) (method *typeMethod, ok bool) {
+	data := typ.methodTable()
+	data.methodSync.Lock()
+	defer data.methodSync.Unlock()
+	for _, it := range data.methodMap[name] {
+		if it.matches(set, typ) {
+			return it, true
+		}
+	}
+	return nil, false
+}
+
+// Returns the traits implemented by the type that have the given method.
+func (set *TypeSet) traitsWithMethod(typ Type, name Id) (out []Type) {
+	var candidates []Type
+	switch def := typ.Def().(type) {
+	case TypeVar:
+		candidates = def.bounds
+	case TypeTrait:
+		candidates = []Type{typ}
+	default:
+		set.implSync.Lock()
+		for it := range set.implMap {
+			candidates = append(candidates, Type{it})
+		}
+		set.implSync.Unlock()
+	}
+
+	for _, it := range candidates {
+		if _, ok := it.Def().(TypeTrait).Method(name); ok && set.Implements(typ, it) {
+			out = append(out, it)
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].String() < out[j].String()
+	})
+	return out
+}
+
+func joinTypes(list []Type) string {
+	out := make([]string, len(list))
+	for n, it := range list {
+		out[n] = fmt.Sprintf("`%s`", it)
+	}
+	return strings.Join(out, ", ")
+}
@@ -0,0 +1,108 @@
+package code
+
+import "sync"
+
+type TypeRecord struct {
+	decl *recordDecl
+	args []Type
+}
+
+type RecordField struct {
+	Name Id
+	Type Type
+}
+
+type recordDecl struct {
+	typeDecl
+
+	fieldSync sync.Mutex
+	fieldDone bool
+	fields    []RecordField
+}
+
+// Declares a new nominal record type with the given type parameters.
+//
+// The returned type is the generic declaration. Fields must be provided
+// by `Define` before the record is used, which allows recursive records.
+func (set *TypeSet) Record(name Id, params ...Type) Type {
+	decl := &recordDecl{}
+	decl.init(set, name, params)
+	return decl.get(params)
+}
+
+func (decl *recordDecl) get(args []Type) Type {
+	return decl.instance(args, func(args []Type) TypeDef {
+		return TypeRecord{decl: decl, args: args}
+	})
+}
+
+func (rec TypeRecord) TypeDef() TypeDef { return rec }
+
+func (rec TypeRecord) instance(args []Type) Type {
+	return rec.decl.get(args)
+}
+
+func (rec TypeRecord) Define(fields ...RecordField) error {
+	rec.decl.fieldSync.Lock()
+	defer rec.decl.fieldSync.Unlock()
+
+	if rec.decl.fieldDone {
+		return errorf(codeInvalidTypeDef, "record `%s` is already defined", rec.decl.name)
+	}
+
+	names := make(map[Id]bool)
+	for _, it := range fields {
+		if names[it.Name] {
+			return errorf(codeInvalidTypeDef, "record `%s` has duplicated field `%s`", rec.decl.name, it.Name)
+		}
+		names[it.Name] = true
+	}
+
+	rec.decl.fields = fields
+	rec.decl.fieldDone = true
+	return nil
+}
+
+func (rec TypeRecord) Name() Id {
+	return rec.decl.name
+}
+
+func (rec TypeRecord) Args() []Type {
+	return rec.args
+}
+
+func (rec TypeRecord) Generic() Type {
+	return rec.decl.get(rec.decl.params)
+}
+
+// Returns the record fields with the type arguments applied.
+func (rec TypeRecord) Fields() []RecordField {
+	rec.decl.fieldSync.Lock()
+	fields := rec.decl.fields
+	rec.decl.fieldSync.Unlock()
+
+	if len(rec.args) == 0 || rec.decl.isGeneric(rec.args) {
+		return fields
+	}
+
+	out := make([]RecordField, len(fields))
+	for n, it := range fields {
+		out[n] = RecordField{Name: it.Name, Type: rec.decl.substitute(rec.args, it.Type)}
+	}
+	return out
+}
+
+func (rec TypeRecord) FieldIndex(name Id) int {
+	rec.decl.fieldSync.Lock()
+	defer rec.decl.fieldSync.Unlock()
+	for n, it := range rec.decl.fields {
+		if it.Name == name {
+			return n
+		}
+	}
+	return -1
+}
+
+func (rec TypeRecord) String() string {
+	return rec.decl.String(rec.args)
+}
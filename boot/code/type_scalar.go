@@ -11,6 +11,16 @@ const (
 	TypeScalarInt
 	TypeScalarNumber
 	TypeScalarString
+	TypeScalarI8
+	TypeScalarI16
+	TypeScalarI32
+	TypeScalarI64
+	TypeScalarU8
+	TypeScalarU16
+	TypeScalarU32
+	TypeScalarU64
+	TypeScalarBytes
+	TypeScalarChar
 )
 
 type TypeScalar struct {
@@ -37,6 +47,26 @@ func (scalar TypeScalar) String() string {
 		return "Number"
 	case TypeScalarString:
 		return "String"
+	case TypeScalarI8:
+		return "i8"
+	case TypeScalarI16:
+		return "i16"
+	case TypeScalarI32:
+		return "i32"
+	case TypeScalarI64:
+		return "i64"
+	case TypeScalarU8:
+		return "u8"
+	case TypeScalarU16:
+		return "u16"
+	case TypeScalarU32:
+		return "u32"
+	case TypeScalarU64:
+		return "u64"
+	case TypeScalarBytes:
+		return "Bytes"
+	case TypeScalarChar:
+		return "Char"
 	default:
 		panic(fmt.Sprintf("invalid TypeScalar kind: %#v", scalar.kind))
 	}
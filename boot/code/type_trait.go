@@ -0,0 +1,103 @@
+package code
+
+import "sync"
+
+// A trait declares a set of method signatures that types can implement.
+//
+// Method signatures refer to the implementing type through the trait's
+// `Self` type variable, which must be the first parameter of every
+// method. When used as a value type, a trait describes a trait object,
+// which holds a value of any type implementing the trait and dispatches
+// its methods dynamically.
+type TypeTrait struct {
+	decl *traitDecl
+}
+
+type TraitMethod struct {
+	Name Id
+	Type Type
+}
+
+type traitDecl struct {
+	name Id
+	self Type
+
+	methodSync sync.Mutex
+	methodDone bool
+	methods    []TraitMethod
+}
+
+func (set *TypeSet) Trait(name Id) Type {
+	decl := &traitDecl{name: name, self: set.Var("Self")}
+	return set.newType(TypeTrait{decl})
+}
+
+func (trait TypeTrait) TypeDef() TypeDef { return trait }
+
+func (trait TypeTrait) Define(methods ...TraitMethod) error {
+	trait.decl.methodSync.Lock()
+	defer trait.decl.methodSync.Unlock()
+
+	if trait.decl.methodDone {
+		return errorf(codeInvalidTypeDef, "trait `%s` is already defined", trait.decl.name)
+	}
+
+	names := make(map[Id]bool)
+	for _, it := range methods {
+		if names[it.Name] {
+			return errorf(codeInvalidTypeDef, "trait `%s` has duplicated method `%s`", trait.decl.name, it.Name)
+		}
+		names[it.Name] = true
+
+		fn, ok := it.Type.Def().(TypeFunc)
+		if !ok {
+			return errorf(codeInvalidTypeDef, "trait method `%s.%s` is not a function", trait.decl.name, it.Name)
+		}
+		if len(fn.params) == 0 || fn.params[0] != trait.decl.self {
+			return errorf(codeInvalidTypeDef, "trait method `%s.%s` must take `Self` as first parameter", trait.decl.name, it.Name)
+		}
+	}
+
+	trait.decl.methods = methods
+	trait.decl.methodDone = true
+	return nil
+}
+
+func (trait TypeTrait) Name() Id {
+	return trait.decl.name
+}
+
+func (trait TypeTrait) Self() Type {
+	return trait.decl.self
+}
+
+func (trait TypeTrait) Methods() []TraitMethod {
+	trait.decl.methodSync.Lock()
+	defer trait.decl.methodSync.Unlock()
+	return trait.decl.methods
+}
+
+func (trait TypeTrait) Method(name Id) (out TraitMethod, ok bool) {
+	for _, it := range trait.Methods() {
+		if it.Name == name {
+			return it, true
+		}
+	}
+	return out, false
+}
+
+// Trait objects can only be used for methods where `Self` appears only as
+// the receiver, since the concrete type is not known statically.
+func (trait TypeTrait) IsObjectSafe(method TraitMethod) bool {
+	fn := method.Type.Def().(TypeFunc)
+	for _, it := range fn.params[1:] {
+		if it.HasVars(trait.decl.self) {
+			return false
+		}
+	}
+	return !fn.result.HasVars(trait.decl.self)
+}
+
+func (trait TypeTrait) String() string {
+	return string(trait.decl.name)
+}
@@ -0,0 +1,211 @@
+package code
+
+import (
+	"errors"
+	"fmt"
+)
+
+var errTypeMismatch = errors.New("type mismatch")
+
+// Replaces every occurrence of the type variables in `vars` by the
+// respective type in `args`.
+func (set *TypeSet) Substitute(typ Type, vars, args []Type) Type {
+	if len(vars) != len(args) {
+		panic("Substitute: variable and argument count mismatch")
+	}
+
+	if len(vars) == 0 {
+		return typ
+	}
+
+	subst := make(map[*typeData]Type, len(vars))
+	for n, it := range vars {
+		subst[it.data] = args[n]
+	}
+
+	return set.mapVars(typ, func(v Type) Type {
+		if out, ok := subst[v.data]; ok {
+			return out
+		}
+		return v
+	})
+}
+
+// Rebuilds a type by mapping all type variables in it.
+func (set *TypeSet) mapVars(typ Type, fn func(v Type) Type) Type {
+	switch def := typ.Def().(type) {
+	case TypeVar:
+		return fn(typ)
+	case TypeScalar, TypeTrait:
+		return typ
+	case TypeTuple:
+		return set.Tuple(set.mapList(def.types, fn)...)
+	case TypeFunc:
+		return set.Func(set.mapVars(def.result, fn), set.mapList(def.params, fn)...)
+	case TypeList:
+		return set.List(set.mapVars(def.elem, fn))
+	case TypeMap:
+		return set.Map(set.mapVars(def.key, fn), set.mapVars(def.val, fn))
+	case typeGeneric:
+		if len(def.Args()) == 0 {
+			return typ
+		}
+		return def.instance(set.mapList(def.Args(), fn))
+	default:
+		panic(fmt.Sprintf("mapVars: unsupported type `%s`", typ))
+	}
+}
+
+func (set *TypeSet) mapList(list []Type, fn func(v Type) Type) []Type {
+	out := make([]Type, len(list))
+	for n, it := range list {
+		out[n] = set.mapVars(it, fn)
+	}
+	return out
+}
+
+// Returns true if the type contains any of the given type variables or,
+// if no variable is given, any fresh variable.
+func (typ Type) HasVars(vars ...Type) (has bool) {
+	typ.Set().mapVars(typ, func(v Type) Type {
+		if len(vars) == 0 {
+			has = has || v.Def().(TypeVar).fresh
+		}
+		for _, it := range vars {
+			has = has || it == v
+		}
+		return v
+	})
+	return has
+}
+
+// Hindley-Milner style unification of types.
+//
+// Only fresh type variables are bound by the unifier. Declared variables
+// (e.g. the type parameters inside a generic function body) are treated as
+// opaque types that only unify with themselves.
+type typeUnifier struct {
+	set  *TypeSet
+	bind map[*typeData]Type
+}
+
+func (set *TypeSet) newUnifier() *typeUnifier {
+	return &typeUnifier{set: set, bind: make(map[*typeData]Type)}
+}
+
+// Returns a fresh variable for each of the given type variables.
+func (u *typeUnifier) fresh(vars []Type) []Type {
+	out := make([]Type, len(vars))
+	for n, it := range vars {
+		v := it.Def().(TypeVar)
+		v.id = u.set.varId.Add(1)
+		v.fresh = true
+		out[n] = u.set.newType(v)
+	}
+	return out
+}
+
+// Instantiates a generic declaration with fresh variables. Other types
+// are returned unchanged.
+func (u *typeUnifier) instantiate(typ Type) Type {
+	if typ.IsGeneric() {
+		def := typ.Def().(typeGeneric)
+		return def.instance(u.fresh(def.Args()))
+	}
+	return typ
+}
+
+// Applies the current bindings to the type.
+func (u *typeUnifier) resolve(typ Type) Type {
+	return u.set.mapVars(typ, func(v Type) Type {
+		if bound, ok := u.bind[v.data]; ok {
+			return u.resolve(bound)
+		}
+		return v
+	})
+}
+
+func (u *typeUnifier) shallow(typ Type) Type {
+	for {
+		bound, ok := u.bind[typ.data]
+		if !ok {
+			return typ
+		}
+		typ = bound
+	}
+}
+
+// Unifies the expected type with the actual type, binding fresh variables
+// as necessary.
+func (u *typeUnifier) unify(expected, actual Type) error {
+	if err := u.unifyTypes(expected, actual); err == errTypeMismatch {
+		return errorf(codeTypeMismatch, "type mismatch: expected `%s`, got `%s`", u.resolve(expected), u.resolve(actual))
+	} else {
+		return err
+	}
+}
+
+func (u *typeUnifier) unifyTypes(a, b Type) error {
+	a, b = u.shallow(a), u.shallow(b)
+	if a == b {
+		return nil
+	}
+
+	if v, ok := a.Def().(TypeVar); ok && v.fresh {
+		return u.bindVar(a, b)
+	}
+	if v, ok := b.Def().(TypeVar); ok && v.fresh {
+		return u.bindVar(b, a)
+	}
+
+	switch da := a.Def().(type) {
+	case TypeTuple:
+		if db, ok := b.Def().(TypeTuple); ok {
+			return u.unifyList(da.types, db.types)
+		}
+	case TypeFunc:
+		if db, ok := b.Def().(TypeFunc); ok {
+			if err := u.unifyList(da.params, db.params); err != nil {
+				return err
+			}
+			return u.unifyTypes(da.result, db.result)
+		}
+	case TypeList:
+		if db, ok := b.Def().(TypeList); ok {
+			return u.unifyTypes(da.elem, db.elem)
+		}
+	case TypeMap:
+		if db, ok := b.Def().(TypeMap); ok {
+			if err := u.unifyTypes(da.key, db.key); err != nil {
+				return err
+			}
+			return u.unifyTypes(da.val, db.val)
+		}
+	case typeGeneric:
+		if db, ok := b.Def().(typeGeneric); ok && da.Generic() == db.Generic() {
+			return u.unifyList(da.Args(), db.Args())
+		}
+	}
+
+	return errTypeMismatch
+}
+
+func (u *typeUnifier) unifyList(a, b []Type) error {
+	if len(a) != len(b) {
+		return errTypeMismatch
+	}
+	for n := range a {
+		if err := u.unifyTypes(a[n], b[n]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u *typeUnifier) bindVar(v, typ Type) error {
+	if u.resolve(typ).HasVars(v) {
+		return errorf(codeInfiniteType, "infinite type: `%s` occurs in `%s`", v, u.resolve(typ))
+	}
+	u.bind[v.data] = typ
+	return nil
+}
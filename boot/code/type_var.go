@@ -0,0 +1,54 @@
+package code
+
+import "fmt"
+
+type TypeVar struct {
+	name   Id
+	id     uint64
+	fresh  bool
+	bounds []Type
+}
+
+// Creates a new type variable. The variable can be bound by traits, which
+// any type argument for the variable must implement.
+func (set *TypeSet) Var(name Id, bounds ...Type) Type {
+	for _, it := range bounds {
+		if _, ok := it.Def().(TypeTrait); !ok {
+			panic(fmt.Sprintf("type variable `%s` bound is not a trait: %s", name, it))
+		}
+	}
+	id := set.varId.Add(1)
+	return set.newType(TypeVar{name: name, id: id, bounds: bounds})
+}
+
+func (v TypeVar) TypeDef() TypeDef { return v }
+
+func (v TypeVar) Name() Id {
+	return v.name
+}
+
+// Fresh variables are created by type inference and can be bound during
+// unification. Declared type variables only unify with themselves.
+func (v TypeVar) Fresh() bool {
+	return v.fresh
+}
+
+func (v TypeVar) Bounds() []Type {
+	return v.bounds
+}
+
+func (v TypeVar) HasBound(trait Type) bool {
+	for _, it := range v.bounds {
+		if it == trait {
+			return true
+		}
+	}
+	return false
+}
+
+func (v TypeVar) String() string {
+	if v.fresh {
+		return fmt.Sprintf("?%s%d", v.name, v.id)
+	}
+	return string(v.name)
+}
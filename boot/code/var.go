@@ -1,10 +1,17 @@
 package code
 
-import "fmt"
+import (
+	"fmt"
 
+	"axlab.dev/bit/base"
+)
+
+// Span is the location of the declaration for function parameters, match
+// bindings, and loop variables, which are not expressions on their own.
 type Var struct {
 	Name Id
 	Type Type
+	Span base.Span
 }
 
 func (expr Var) IsExpr() {}
@@ -12,3 +19,12 @@ func (expr Var) IsExpr() {}
 func (expr Var) String() string {
 	return fmt.Sprintf("Var(%s: %s)", expr.Name, expr.Type)
 }
+
+// Returns the location of the declaration, or the given span if the
+// variable has none.
+func (expr Var) declSpan(span base.Span) base.Span {
+	if expr.Span.Valid() {
+		return expr.Span
+	}
+	return span
+}
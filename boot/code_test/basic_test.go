@@ -1,6 +1,7 @@
 package code_tests
 
 import (
+	"math/big"
 	"testing"
 
 	"axlab.dev/bit/code"
@@ -32,6 +33,6 @@ func TestPrint(t *testing.T) {
 
 	program.Append(block)
 	test.ExpectStdOut = "The answer to life, the universe, and everything is 42\n"
-	test.ExpectResult = []any{"The answer to life, the universe, and everything is", int64(42)}
+	test.ExpectResult = []any{"The answer to life, the universe, and everything is", big.NewInt(42)}
 	test.Check()
 }
@@ -0,0 +1,134 @@
+package code_tests
+
+import (
+	"testing"
+
+	"axlab.dev/bit/code"
+)
+
+func TestBytes(t *testing.T) {
+	test := NewTest(t)
+	types := test.Program.Types()
+
+	data, err := code.BytesHex("de ad_BE EF")
+	test.NoError(err)
+	test.Equal([]byte{0xde, 0xad, 0xbe, 0xef}, data.Value)
+
+	varB := code.Var{Name: "b"}
+	typeStr := types.Scalar(code.TypeScalarString)
+	typeBytes := types.Scalar(code.TypeScalarBytes)
+
+	test.Program.Append(code.ExprNew(code.Block{
+		List: []code.Expr{
+			code.ExprNew(code.Let{Decl: varB, Init: code.ExprNew(data)}),
+			code.ExprNew(code.Print{Args: []code.Expr{
+				code.ExprNew(varB),
+				code.ExprNew(code.Index{Value: code.ExprNew(varB), Index: num(1)}),
+				code.ExprNew(code.Slice{Value: code.ExprNew(varB), From: num(1), To: num(3)}),
+				method(code.ExprNew(varB), "len"),
+				method(code.ExprNew(varB), "hex"),
+				code.ExprNew(code.Convert{
+					Value: code.ExprNew(code.Convert{Value: str("héllo"), Type: typeBytes}),
+					Type:  typeStr,
+				}),
+				method(code.ExprNew(code.Convert{Value: str("é"), Type: typeBytes}), "hex"),
+			}}),
+		},
+	}))
+
+	test.ExpectStdOut = "x\"deadbeef\" 173 x\"adbe\" 4 deadbeef héllo c3a9\n"
+	test.Check()
+}
+
+func TestBytesToString(t *testing.T) {
+	test := NewTest(t)
+	types := test.Program.Types()
+
+	typeResult := types.Result(types.Scalar(code.TypeScalarString), types.Error())
+	argR := code.Var{Name: "r", Type: typeResult}
+	bindV := code.Var{Name: "v"}
+	bindE := code.Var{Name: "e"}
+
+	test.Program.Append(code.ExprNew(code.Block{
+		List: []code.Expr{
+			code.ExprNew(code.Func{
+				Name:   "show",
+				Params: []code.Var{argR},
+				Body: code.ExprNew(code.Match{
+					Value: code.ExprNew(argR),
+					Cases: []code.MatchCase{
+						{Variant: "Ok", Bind: bindV, Body: stmt(code.Print{Args: []code.Expr{code.ExprNew(bindV)}})},
+						{Variant: "Err", Bind: bindE, Body: stmt(code.Print{Args: []code.Expr{
+							code.ExprNew(code.Field{Value: code.ExprNew(bindE), Name: "message"}),
+						}})},
+					},
+				}),
+			}),
+			call("show", method(code.ExprNew(code.Bytes{Value: []byte("abc")}), "to_string")),
+			call("show", method(code.ExprNew(code.Bytes{Value: []byte{'a', 0xff, 'b'}}), "to_string")),
+		},
+	}))
+
+	test.ExpectStdOut = "abc\ninvalid UTF-8 at byte offset 1\n"
+	test.Check()
+}
+
+func TestChar(t *testing.T) {
+	test := NewTest(t)
+	types := test.Program.Types()
+	typeStr := types.Scalar(code.TypeScalarString)
+	typeChar := types.Scalar(code.TypeScalarChar)
+
+	varC := code.Var{Name: "c"}
+	test.Program.Append(code.ExprNew(code.Block{
+		List: []code.Expr{
+			code.ExprNew(code.For{
+				Vars:  []code.Var{varC},
+				Value: method(str("aé😀"), "chars"),
+				Body: code.ExprNew(code.Print{Args: []code.Expr{
+					code.ExprNew(varC),
+					as(test, code.TypeScalarU32, code.ExprNew(varC)),
+				}}),
+			}),
+			code.ExprNew(code.Print{Args: []code.Expr{
+				code.ExprNew(code.Convert{Value: num(0x3bb), Type: typeChar}),
+				code.ExprNew(code.Convert{Value: code.ExprNew(code.Char{Value: 'z'}), Type: typeStr}),
+			}}),
+		},
+	}))
+
+	test.ExpectStdOut = "a 97\né 233\n😀 128512\nλ z\n"
+	test.Check()
+}
+
+func TestBytesAndCharErrors(t *testing.T) {
+	test := NewTest(t)
+
+	_, err := code.BytesHex("abc")
+	test.ErrorContains(err, "odd number of hex digits in bytes literal")
+
+	_, err = code.BytesHex("ag")
+	test.ErrorContains(err, "invalid hex digit `g` at offset 1 in bytes literal")
+
+	test.Program.Append(code.ExprNew(code.Index{Value: code.ExprNew(code.Bytes{Value: []byte{1}}), Index: num(1)}))
+	test.CheckRuntimeError("index out of bounds: index is 1 but length is 1")
+
+	test = NewTest(t)
+	test.Program.Append(code.ExprNew(code.Convert{
+		Value: code.ExprNew(code.Bytes{Value: []byte{0xc3}}),
+		Type:  test.Program.Types().Scalar(code.TypeScalarString),
+	}))
+	test.CheckRuntimeError("invalid UTF-8 at byte offset 0")
+
+	test = NewTest(t)
+	test.Program.Append(as(test, code.TypeScalarChar, num(0xD800)))
+	test.CheckRuntimeError("invalid Unicode code point `55296`")
+
+	test = NewTest(t)
+	test.Program.Append(as(test, code.TypeScalarU8, code.ExprNew(code.Char{Value: 'Ā'})))
+	test.CheckRuntimeError("integer overflow: `256` does not fit in `u8`")
+
+	test = NewTest(t)
+	test.Program.Append(code.ExprNew(code.Char{Value: 0x110000}))
+	test.CheckCompileError("invalid Unicode code point `U+110000` in char literal")
+}
@@ -0,0 +1,158 @@
+package code_tests
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"axlab.dev/bit/base"
+	"axlab.dev/bit/code"
+)
+
+func TestCodeCatalog(t *testing.T) {
+	test := NewTest(t)
+
+	codes := code.Codes()
+	test.NotEmpty(codes)
+	for _, it := range codes {
+		test.Regexp(`^[EW]\d{4}$`, it.Code)
+		test.NotEmpty(it.Title, "code %s has no title", it.Code)
+		test.NotEmpty(it.Explain, "code %s has no explanation", it.Code)
+	}
+
+	info, ok := code.LookupCode("E0001")
+	test.True(ok)
+	test.Equal("name declared twice in the same scope", info.Title)
+	test.Contains(info.Explain, "let x = 2")
+
+	_, ok = code.LookupCode("E9999")
+	test.False(ok)
+}
+
+func TestTypeDefinitionErrors(t *testing.T) {
+	test := NewTest(t)
+	types := test.Program.Types()
+	typeInt := types.Scalar(code.TypeScalarInt)
+
+	point := types.Record("Point")
+	err := point.Def().(code.TypeRecord).Define(
+		code.RecordField{Name: "x", Type: typeInt},
+		code.RecordField{Name: "x", Type: typeInt},
+	)
+	test.EqualError(err, "record `Point` has duplicated field `x`")
+	test.checkCode(err)
+
+	color := types.Enum("Color")
+	test.NoError(color.Def().(code.TypeEnum).Define(code.EnumVariant{Name: "Red"}))
+	err = color.Def().(code.TypeEnum).Define(code.EnumVariant{Name: "Red"})
+	test.EqualError(err, "enum `Color` is already defined")
+	test.checkCode(err)
+}
+
+// Errors in the compiler sources that are created without a code. These
+// are internal and replaced by a diagnostic before being reported.
+var uncodedErrors = map[string]bool{
+	"errTypeMismatch": true,
+}
+
+// Checks the compiler sources so that every diagnostic is created with a
+// code constant registered in the catalog, and every constant is used.
+//
+// Errors must be created with `errorf` or as a diagnostic. Plain errors
+// from `fmt.Errorf` must wrap another error with `%w`, and `errors.New`
+// is only allowed for the internal errors in uncodedErrors.
+func TestCodesRegistered(t *testing.T) {
+	test := NewTest(t)
+
+	fileSet := token.NewFileSet()
+	dir := filepath.Join(base.ProjectDir(), "boot", "code")
+	pkgs, err := parser.ParseDir(fileSet, dir, nil, 0)
+	test.NoError(err)
+
+	consts := make(map[string]string)
+	used := make(map[string]bool)
+	checkCode := func(expr ast.Expr) {
+		ident, ok := expr.(*ast.Ident)
+		if !ok {
+			test.Fail("diagnostic code is not a constant", "at %s", fileSet.Position(expr.Pos()))
+			return
+		}
+		used[ident.Name] = true
+	}
+
+	for _, file := range pkgs["code"].Files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.FuncDecl:
+				// the helper creating diagnostics from its code argument
+				return node.Name.Name != "errorf"
+			case *ast.ValueSpec:
+				if len(node.Names) == 1 && uncodedErrors[node.Names[0].Name] {
+					return false
+				}
+				for n, name := range node.Names {
+					if n >= len(node.Values) || !strings.HasPrefix(name.Name, "code") {
+						continue
+					}
+					if lit, ok := node.Values[n].(*ast.BasicLit); ok {
+						consts[name.Name], _ = strconv.Unquote(lit.Value)
+					}
+				}
+			case *ast.CallExpr:
+				if fn, ok := node.Fun.(*ast.Ident); ok && fn.Name == "errorf" {
+					checkCode(node.Args[0])
+				}
+				if isCall(node, "errors", "New") {
+					test.Fail("error without a code", "at %s", fileSet.Position(node.Pos()))
+				}
+				if isCall(node, "fmt", "Errorf") {
+					format, ok := node.Args[0].(*ast.BasicLit)
+					if !ok || !strings.Contains(format.Value, "%w") {
+						test.Fail("error without a code", "at %s", fileSet.Position(node.Pos()))
+					}
+				}
+			case *ast.CompositeLit:
+				if sel, ok := node.Type.(*ast.SelectorExpr); ok && sel.Sel.Name == "Diagnostic" {
+					hasCode := false
+					for _, it := range node.Elts {
+						if kv, ok := it.(*ast.KeyValueExpr); ok && isIdent(kv.Key, "Code") {
+							checkCode(kv.Value)
+							hasCode = true
+						}
+					}
+					test.True(hasCode, "diagnostic without a code at %s", fileSet.Position(node.Pos()))
+				}
+			}
+			return true
+		})
+	}
+
+	test.NotEmpty(consts)
+	registered := make(map[string]bool)
+	for name, value := range consts {
+		_, ok := code.LookupCode(value)
+		test.True(ok, "code %s (%s) is not in the catalog", value, name)
+		test.True(used[name], "code %s (%s) is never used", value, name)
+		registered[value] = true
+	}
+	for name := range used {
+		test.Contains(consts, name, "unknown code constant %s", name)
+	}
+	for _, it := range code.Codes() {
+		test.True(registered[it.Code], "catalog code %s has no constant", it.Code)
+	}
+}
+
+func isCall(call *ast.CallExpr, pkg, name string) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	return ok && isIdent(sel.X, pkg) && sel.Sel.Name == name
+}
+
+func isIdent(expr ast.Expr, name string) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == name
+}
@@ -0,0 +1,103 @@
+package code_tests
+
+import (
+	"testing"
+
+	"axlab.dev/bit/code"
+)
+
+func TestForwardReferences(t *testing.T) {
+	test := NewTest(t)
+	program := &test.Program
+	types := program.Types()
+	typeStr := types.Scalar(code.TypeScalarString)
+
+	nat := types.Enum("Nat")
+	test.NoError(nat.Def().(code.TypeEnum).Define(
+		code.EnumVariant{Name: "Zero"},
+		code.EnumVariant{Name: "Succ", Type: nat},
+	))
+
+	argN := code.Var{Name: "n", Type: nat}
+	bindM := code.Var{Name: "m"}
+	parity := func(name, other code.Id, zero string) code.Func {
+		return code.Func{
+			Name:   name,
+			Params: []code.Var{argN},
+			Result: typeStr,
+			Body: code.ExprNew(code.Match{
+				Value: code.ExprNew(argN),
+				Cases: []code.MatchCase{
+					{Variant: "Zero", Body: str(zero)},
+					{Variant: "Succ", Bind: bindM, Body: call(other, code.ExprNew(bindM))},
+				},
+			}),
+		}
+	}
+
+	natOf := func(n int) code.Expr {
+		out := code.ExprNew(code.Variant{Type: nat, Name: "Zero"})
+		for ; n > 0; n-- {
+			out = code.ExprNew(code.Variant{Type: nat, Name: "Succ", Value: out})
+		}
+		return out
+	}
+
+	selfNat := code.Var{Name: "self", Type: nat}
+	program.Append(
+		code.ExprNew(code.Print{Args: []code.Expr{call("is_even", natOf(3)), call("is_odd", natOf(3))}}),
+		code.ExprNew(code.Print{Args: []code.Expr{method(natOf(4), "parity")}}),
+		code.ExprNew(parity("is_even", "is_odd", "even")),
+		code.ExprNew(parity("is_odd", "is_even", "odd")),
+		code.ExprNew(code.Impl{
+			Type: nat,
+			Methods: []code.Func{
+				{
+					Name:   "parity",
+					Params: []code.Var{selfNat},
+					Result: typeStr,
+					Body:   call("is_even", code.ExprNew(selfNat)),
+				},
+			},
+		}),
+	)
+
+	test.ExpectStdOut = "odd even\neven\n"
+	test.Check()
+}
+
+func TestForwardReferenceGlobal(t *testing.T) {
+	test := NewTest(t)
+	typeNum := test.Program.Types().Scalar(code.TypeScalarNumber)
+
+	varA := code.Var{Name: "a"}
+	test.Program.Append(
+		code.ExprNew(code.Let{Decl: varA, Init: call("double", num(21))}),
+		code.ExprNew(code.Print{Args: []code.Expr{code.ExprNew(varA)}}),
+		code.ExprNew(code.Func{
+			Name:   "double",
+			Params: []code.Var{{Name: "x", Type: typeNum}},
+			Result: typeNum,
+			Body:   binary(code.OpMul, code.ExprNew(code.Var{Name: "x"}), num(2)),
+		}),
+	)
+
+	test.ExpectStdOut = "42\n"
+	test.Check()
+}
+
+func TestDeclErrors(t *testing.T) {
+	test := NewTest(t)
+	test.Program.Append(
+		code.ExprNew(code.Func{Name: "f", Body: num(1)}),
+		code.ExprNew(code.Func{Name: "f", Body: num(2)}),
+	)
+	test.CheckCompileError("`f` is already declared")
+
+	test = NewTest(t)
+	test.Program.Append(code.ExprNew(code.Block{List: []code.Expr{
+		call("g"),
+		code.ExprNew(code.Func{Name: "g", Body: num(1)}),
+	}}))
+	test.CheckCompileError("variable `g` not in the scope")
+}
@@ -0,0 +1,75 @@
+package code_tests
+
+import (
+	"testing"
+
+	"axlab.dev/bit/base"
+	"axlab.dev/bit/code"
+)
+
+func TestRuntimeErrorStack(t *testing.T) {
+	test := NewTest(t)
+	program := &test.Program
+	types := program.Types()
+
+	at := func(line, column int) base.Span {
+		return base.Span{File: "main.bit", Sta: base.Pos{Line: line, Column: column}}
+	}
+
+	argL := code.Var{Name: "l", Type: types.List(types.Scalar(code.TypeScalarNumber))}
+
+	program.Append(code.ExprNew(code.Block{
+		List: []code.Expr{
+			code.ExprNew(code.Func{
+				Name:   "get",
+				Params: []code.Var{argL},
+				Result: types.Scalar(code.TypeScalarNumber),
+				Body:   code.ExprAt(at(2, 5), code.Index{Value: code.ExprNew(argL), Index: num(5)}),
+			}),
+			code.ExprNew(code.Func{
+				Name:   "first",
+				Result: types.Scalar(code.TypeScalarNumber),
+				Body:   code.ExprAt(at(6, 5), code.Call{Func: code.ExprNew(code.Var{Name: "get"}), Args: []code.Expr{numList(1, 2)}}),
+			}),
+			code.ExprAt(at(9, 1), code.Call{Func: code.ExprNew(code.Var{Name: "first"})}),
+		},
+	}))
+
+	_, err := test.run()
+	test.ErrorContains(err, "index out of bounds: index is 5 but length is 2")
+
+	runtimeErr, ok := err.(*code.RuntimeError)
+	test.True(ok, "expected a RuntimeError")
+	test.Equal(at(2, 5), runtimeErr.Span())
+	test.Equal([]code.StackEntry{
+		{Func: "get", Span: at(2, 5)},
+		{Func: "first", Span: at(6, 5)},
+		{Span: at(9, 1)},
+	}, runtimeErr.Stack)
+
+	test.Equal(base.Text(`
+		error: index out of bounds: index is 5 but length is 2
+		    at `+"`get`"+` (main.bit:2:5)
+		    at `+"`first`"+` (main.bit:6:5)
+		    at program (main.bit:9:1)
+	`), runtimeErr.Trace()+"\n")
+
+	diag := runtimeErr.Diagnostic()
+	test.Equal(at(2, 5), diag.Span)
+	test.Equal("index out of bounds: index is 5 but length is 2", diag.Message)
+	test.Equal([]base.Related{
+		{Span: at(6, 5), Message: "called from `first`"},
+		{Span: at(9, 1), Message: "called from program"},
+	}, diag.Related)
+}
+
+func TestRuntimeErrorWithoutSpans(t *testing.T) {
+	test := NewTest(t)
+	test.Program.Append(code.ExprNew(code.Index{Value: numList(1), Index: num(3)}))
+
+	_, err := test.run()
+	runtimeErr, ok := err.(*code.RuntimeError)
+	test.True(ok, "expected a RuntimeError")
+	test.False(runtimeErr.Span().Valid())
+	test.Equal("error: index out of bounds: index is 3 but length is 1\n    at program", runtimeErr.Trace())
+}
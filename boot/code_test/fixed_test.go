@@ -0,0 +1,87 @@
+package code_tests
+
+import (
+	"testing"
+
+	"axlab.dev/bit/code"
+)
+
+func TestFixedWidthOps(t *testing.T) {
+	test := NewTest(t)
+	u8 := func(v int64) code.Expr { return as(test, code.TypeScalarU8, num(v)) }
+	i8 := func(v int64) code.Expr { return as(test, code.TypeScalarI8, num(v)) }
+	u32 := func(v int64) code.Expr { return as(test, code.TypeScalarU32, num(v)) }
+
+	test.Program.Append(code.ExprNew(code.Print{Args: []code.Expr{
+		binary(code.OpAnd, u8(0xF0), u8(0x3C)),
+		binary(code.OpOr, u8(0xF0), u8(0x0F)),
+		binary(code.OpXor, u8(0xFF), u8(0x0F)),
+		code.ExprNew(code.Unary{Op: code.OpBitNot, Value: u8(1)}),
+		code.ExprNew(code.Unary{Op: code.OpBitNot, Value: i8(0)}),
+		binary(code.OpShl, u8(0x81), num(1)),
+		binary(code.OpShr, i8(-128), num(2)),
+		binary(code.OpShr, u8(0x80), num(2)),
+		binary(code.OpWrapAdd, u8(250), u8(10)),
+		binary(code.OpWrapSub, u8(0), u8(1)),
+		binary(code.OpWrapMul, i8(64), i8(2)),
+		binary(code.OpAdd, u32(4000000000), u32(294967295)),
+		method(u8(0x81), "rotate_left", toInt(test, num(1))),
+		method(u8(0x81), "rotate_right", toInt(test, num(9))),
+		method(u32(0xF000000F), "popcount"),
+		method(i8(-1), "popcount"),
+		code.ExprNew(code.Unary{Op: code.OpNeg, Value: i8(127)}),
+	}}))
+
+	test.ExpectStdOut = "48 255 240 254 -1 2 -32 32 4 255 -128 4294967295 3 192 8 8 -127\n"
+	test.Check()
+}
+
+func TestFixedWidthErrors(t *testing.T) {
+	test := NewTest(t)
+	test.Program.Append(binary(code.OpAdd, as(test, code.TypeScalarU8, num(200)), as(test, code.TypeScalarU8, num(100))))
+	test.CheckRuntimeError("integer overflow: `200 + 100` does not fit in `u8`")
+
+	test = NewTest(t)
+	test.Program.Append(binary(code.OpSub, as(test, code.TypeScalarU16, num(0)), as(test, code.TypeScalarU16, num(1))))
+	test.CheckRuntimeError("integer overflow: `0 - 1` does not fit in `u16`")
+
+	test = NewTest(t)
+	test.Program.Append(binary(code.OpMul, as(test, code.TypeScalarU64, num(1<<32)), as(test, code.TypeScalarU64, num(1<<32))))
+	test.CheckRuntimeError("integer overflow: `4294967296 * 4294967296` does not fit in `u64`")
+
+	test = NewTest(t)
+	test.Program.Append(binary(code.OpDiv, as(test, code.TypeScalarI8, num(-128)), as(test, code.TypeScalarI8, num(-1))))
+	test.CheckRuntimeError("integer overflow: `-128 / -1` does not fit in `i8`")
+
+	test = NewTest(t)
+	test.Program.Append(code.ExprNew(code.Unary{Op: code.OpNeg, Value: as(test, code.TypeScalarI16, num(-32768))}))
+	test.CheckRuntimeError("integer overflow: `-(-32768)` does not fit in `i16`")
+
+	test = NewTest(t)
+	test.Program.Append(as(test, code.TypeScalarU8, num(256)))
+	test.CheckRuntimeError("integer overflow: `256` does not fit in `u8`")
+
+	test = NewTest(t)
+	test.Program.Append(binary(code.OpShl, as(test, code.TypeScalarU8, num(1)), num(8)))
+	test.CheckRuntimeError("shift count `8` out of range for `u8`")
+
+	test = NewTest(t)
+	test.Program.Append(binary(code.OpAnd, as(test, code.TypeScalarU8, num(1)), as(test, code.TypeScalarU16, num(1))))
+	test.CheckCompileError("operator `&` is not defined for `u8` and `u16`")
+
+	test = NewTest(t)
+	test.Program.Append(binary(code.OpAdd, as(test, code.TypeScalarU8, num(1)), num(1)))
+	test.CheckCompileError("operator `+` is not defined for `u8` and `Number`")
+
+	test = NewTest(t)
+	test.Program.Append(code.ExprNew(code.Unary{Op: code.OpNeg, Value: as(test, code.TypeScalarU8, num(1))}))
+	test.CheckCompileError("operator `-` is not defined for `u8`")
+
+	test = NewTest(t)
+	test.Program.Append(code.ExprNew(code.Unary{Op: code.OpBitNot, Value: num(1)}))
+	test.CheckCompileError("operator `~` is not defined for `Number`")
+}
+
+func as(test *Test, kind code.TypeScalarKind, expr code.Expr) code.Expr {
+	return code.ExprNew(code.Convert{Value: expr, Type: test.Program.Types().Scalar(kind)})
+}
@@ -0,0 +1,115 @@
+package code_tests
+
+import (
+	"testing"
+
+	"axlab.dev/bit/base"
+	"axlab.dev/bit/code"
+)
+
+func TestPrintFormatting(t *testing.T) {
+	test := NewTest(t)
+	program := &test.Program
+	types := program.Types()
+
+	typeNum := types.Scalar(code.TypeScalarNumber)
+	typeStr := types.Scalar(code.TypeScalarString)
+	point := types.Record("Point")
+	test.NoError(point.Def().(code.TypeRecord).Define(
+		code.RecordField{Name: "x", Type: typeNum},
+		code.RecordField{Name: "name", Type: typeStr},
+	))
+
+	typeT := types.Var("T")
+	tree := types.Enum("Tree", typeT)
+	treeNum := mustInstance(test, tree, typeNum)
+	test.NoError(tree.Def().(code.TypeEnum).Define(
+		code.EnumVariant{Name: "Leaf"},
+		code.EnumVariant{Name: "Node", Type: types.Tuple(typeT, types.List(mustInstance(test, tree, typeT)))},
+	))
+
+	parseFloat := func(text string) code.Expr {
+		expr, err := code.NumberLiteral(base.Span{}, text)
+		test.NoError(err)
+		return expr
+	}
+
+	leaf := code.ExprNew(code.Variant{Type: treeNum, Name: "Leaf"})
+	node := code.ExprNew(code.Variant{Type: treeNum, Name: "Node", Value: code.ExprNew(code.Tuple{Items: []code.Expr{
+		num(1),
+		code.ExprNew(code.List{Items: []code.Expr{leaf, leaf}}),
+	}})})
+
+	program.Append(code.ExprNew(code.Print{Args: []code.Expr{
+		str("top"),
+		code.ExprNew(code.Char{Value: 'c'}),
+		code.ExprNew(code.Tuple{Items: []code.Expr{str("a\"b"), code.ExprNew(code.Char{Value: '\n'})}}),
+		code.ExprNew(code.Tuple{Items: []code.Expr{num(1)}}),
+		code.ExprNew(code.Tuple{}),
+		code.ExprNew(code.List{Items: []code.Expr{parseFloat("0.1"), parseFloat("2f"), parseFloat("1e21")}}),
+		code.ExprNew(code.Record{Type: point, Fields: []code.Expr{num(1), str("p")}}),
+		code.ExprNew(code.Map{Entries: []code.MapEntry{{Key: str("k"), Value: numList(1, 2)}}}),
+		code.ExprNew(code.Variant{Type: types.Option(typeStr), Name: "Some", Value: str("s")}),
+		code.ExprNew(code.Variant{Type: types.Option(typeStr), Name: "None"}),
+		node,
+	}}))
+
+	test.ExpectStdOut = `top c ("a\"b", '\n') (1,) () [0.1, 2.0, 1e+21] Point { x: 1, name: "p" } {"k": [1, 2]} Some("s") None Node(1, [Leaf, Leaf])` + "\n"
+	test.Check()
+}
+
+func TestShowOverride(t *testing.T) {
+	test := NewTest(t)
+	program := &test.Program
+	types := program.Types()
+
+	typeNum := types.Scalar(code.TypeScalarNumber)
+	typeStr := types.Scalar(code.TypeScalarString)
+	money := types.Record("Money")
+	test.NoError(money.Def().(code.TypeRecord).Define(
+		code.RecordField{Name: "cents", Type: typeNum},
+	))
+
+	self := code.Var{Name: "self", Type: money}
+	cents := code.ExprNew(code.Field{Value: code.ExprNew(self), Name: "cents"})
+	showBody, err := code.StrLiteral(`${cents / 100}.{cents % 100}`, false, parseWith(map[string]code.Expr{
+		"cents / 100": binary(code.OpDiv, cents, num(100)),
+		"cents % 100": binary(code.OpRem, cents, num(100)),
+	}))
+	test.NoError(err)
+
+	newMoney := func(cents int64) code.Expr {
+		return code.ExprNew(code.Record{Type: money, Fields: []code.Expr{num(cents)}})
+	}
+
+	varM := code.Var{Name: "m"}
+	varS := code.Var{Name: "s", Type: types.Show()}
+	interp, err := code.StrLiteral(`total: {m}`, false, parseWith(map[string]code.Expr{"m": code.ExprNew(varM)}))
+	test.NoError(err)
+
+	program.Append(
+		code.ExprNew(code.Impl{
+			Trait: types.Show(),
+			Type:  money,
+			Methods: []code.Func{
+				{Name: "show", Params: []code.Var{self}, Result: typeStr, Body: showBody},
+			},
+		}),
+		code.ExprNew(code.Block{
+			List: []code.Expr{
+				code.ExprNew(code.Let{Decl: varM, Init: newMoney(1250)}),
+				code.ExprNew(code.Let{Decl: varS, Init: newMoney(99)}),
+				code.ExprNew(code.Print{Args: []code.Expr{
+					code.ExprNew(varM),
+					code.ExprNew(code.List{Items: []code.Expr{newMoney(1), newMoney(200)}}),
+					code.ExprNew(varS),
+				}}),
+				interp,
+			},
+		}),
+	)
+
+	test.ExpectStdOut = "$12.50 [$0.1, $2.0] $0.99\n"
+	test.ExpectResult = "total: $12.50"
+	test.Check()
+}
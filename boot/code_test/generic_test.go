@@ -0,0 +1,230 @@
+package code_tests
+
+import (
+	"testing"
+
+	"axlab.dev/bit/code"
+)
+
+func TestGenericFunc(t *testing.T) {
+	test := NewTest(t)
+	program := &test.Program
+	types := program.Types()
+
+	typeT := types.Var("T")
+	argX := code.Var{Name: "x", Type: typeT}
+
+	block := code.ExprNew(code.Block{
+		List: []code.Expr{
+			code.ExprNew(code.Func{
+				Name:     "id",
+				Generics: []code.Type{typeT},
+				Params:   []code.Var{argX},
+				Result:   typeT,
+				Body:     code.ExprNew(argX),
+			}),
+			code.ExprNew(code.Print{
+				Args: []code.Expr{
+					call("id", code.ExprNew(code.Number{Value: 1})),
+					call("id", code.ExprNew(code.Str{Value: "abc"})),
+				},
+			}),
+		},
+	})
+
+	program.Append(block)
+	test.ExpectStdOut = "1 abc\n"
+	test.Check()
+}
+
+func TestGenericRecord(t *testing.T) {
+	test := NewTest(t)
+	program := &test.Program
+	types := program.Types()
+
+	typeNum := types.Scalar(code.TypeScalarNumber)
+	typeStr := types.Scalar(code.TypeScalarString)
+
+	typeA, typeB := types.Var("A"), types.Var("B")
+	pair := types.Record("Pair", typeA, typeB)
+	test.NoError(pair.Def().(code.TypeRecord).Define(
+		code.RecordField{Name: "fst", Type: typeA},
+		code.RecordField{Name: "snd", Type: typeB},
+	))
+	test.True(pair.IsGeneric())
+	test.Equal("Pair[A, B]", pair.String())
+
+	pairNumStr, err := types.Instance(pair, typeNum, typeStr)
+	test.NoError(err)
+	test.Equal("Pair[Number, String]", pairNumStr.String())
+	test.False(pairNumStr.IsGeneric())
+
+	again, _ := types.Instance(pair, typeNum, typeStr)
+	test.Equal(pairNumStr, again)
+
+	typeX, typeY := types.Var("X"), types.Var("Y")
+	pairXY, _ := types.Instance(pair, typeX, typeY)
+	pairYX, _ := types.Instance(pair, typeY, typeX)
+	argP := code.Var{Name: "p", Type: pairXY}
+
+	swapped := code.Var{Name: "swapped", Type: mustInstance(test, pair, typeStr, typeNum)}
+	block := code.ExprNew(code.Block{
+		List: []code.Expr{
+			code.ExprNew(code.Func{
+				Name:     "swap",
+				Generics: []code.Type{typeX, typeY},
+				Params:   []code.Var{argP},
+				Result:   pairYX,
+				Body: code.ExprNew(code.Record{
+					Type: pair,
+					Fields: []code.Expr{
+						code.ExprNew(code.Field{Value: code.ExprNew(argP), Name: "snd"}),
+						code.ExprNew(code.Field{Value: code.ExprNew(argP), Name: "fst"}),
+					},
+				}),
+			}),
+			code.ExprNew(code.Let{
+				Decl: swapped,
+				Init: call("swap", code.ExprNew(code.Record{
+					Type: pair,
+					Fields: []code.Expr{
+						code.ExprNew(code.Number{Value: 42}),
+						code.ExprNew(code.Str{Value: "answer"}),
+					},
+				})),
+			}),
+			code.ExprNew(code.Print{
+				Args: []code.Expr{
+					code.ExprNew(code.Field{Value: code.ExprNew(swapped), Name: "fst"}),
+					code.ExprNew(code.Field{Value: code.ExprNew(swapped), Name: "snd"}),
+				},
+			}),
+		},
+	})
+
+	program.Append(block)
+	test.ExpectStdOut = "answer 42\n"
+	test.Check()
+}
+
+func TestGenericEnum(t *testing.T) {
+	test := NewTest(t)
+	program := &test.Program
+	types := program.Types()
+
+	typeNum := types.Scalar(code.TypeScalarNumber)
+	typeT := types.Var("T")
+	maybe := types.Enum("Maybe", typeT)
+	test.NoError(maybe.Def().(code.TypeEnum).Define(
+		code.EnumVariant{Name: "None"},
+		code.EnumVariant{Name: "Some", Type: typeT},
+	))
+
+	typeU := types.Var("U")
+	argM := code.Var{Name: "m", Type: mustInstance(test, maybe, typeU)}
+	argD := code.Var{Name: "d", Type: typeU}
+	bindV := code.Var{Name: "v"}
+
+	block := code.ExprNew(code.Block{
+		List: []code.Expr{
+			code.ExprNew(code.Func{
+				Name:     "or_else",
+				Generics: []code.Type{typeU},
+				Params:   []code.Var{argM, argD},
+				Result:   typeU,
+				Body: code.ExprNew(code.Match{
+					Value: code.ExprNew(argM),
+					Cases: []code.MatchCase{
+						{Variant: "Some", Bind: bindV, Body: code.ExprNew(bindV)},
+						{Body: code.ExprNew(argD)},
+					},
+				}),
+			}),
+			code.ExprNew(code.Print{
+				Args: []code.Expr{
+					call("or_else",
+						code.ExprNew(code.Variant{Type: maybe, Name: "Some", Value: code.ExprNew(code.Number{Value: 1})}),
+						code.ExprNew(code.Number{Value: 2}),
+					),
+					call("or_else",
+						code.ExprNew(code.Variant{Type: mustInstance(test, maybe, typeNum), Name: "None"}),
+						code.ExprNew(code.Number{Value: 3}),
+					),
+				},
+			}),
+		},
+	})
+
+	program.Append(block)
+	test.ExpectStdOut = "1 3\n"
+	test.Check()
+}
+
+func TestGenericErrors(t *testing.T) {
+	test := NewTest(t)
+	types := test.Program.Types()
+
+	typeT := types.Var("T")
+	argA := code.Var{Name: "a", Type: typeT}
+	argB := code.Var{Name: "b", Type: typeT}
+
+	test.Program.Append(code.ExprNew(code.Block{
+		List: []code.Expr{
+			code.ExprNew(code.Func{
+				Name:     "same",
+				Generics: []code.Type{typeT},
+				Params:   []code.Var{argA, argB},
+				Result:   typeT,
+				Body:     code.ExprNew(argB),
+			}),
+			call("same", code.ExprNew(code.Number{Value: 1}), code.ExprNew(code.Str{Value: "x"})),
+		},
+	}))
+	test.CheckCompileError("type mismatch: expected `Number`, got `String`")
+
+	test = NewTest(t)
+	types = test.Program.Types()
+
+	typeT = types.Var("T")
+	argA = code.Var{Name: "a", Type: typeT}
+
+	test.Program.Append(code.ExprNew(code.Block{
+		List: []code.Expr{
+			code.ExprNew(code.Func{
+				Name:     "bad",
+				Generics: []code.Type{typeT},
+				Params:   []code.Var{argA},
+				Result:   typeT,
+				Body:     code.ExprNew(code.Number{Value: 1}),
+			}),
+		},
+	}))
+	test.CheckCompileError("expected `T`, got `Number`")
+
+	// polymorphic recursion needs a new instance for each call
+	test = NewTest(t)
+	types = test.Program.Types()
+
+	typeT = types.Var("T")
+	argA = code.Var{Name: "a", Type: typeT}
+
+	test.Program.Append(code.ExprNew(code.Func{
+		Name:     "nest",
+		Generics: []code.Type{typeT},
+		Params:   []code.Var{argA},
+		Body:     call("nest", code.ExprNew(code.List{Items: []code.Expr{code.ExprNew(argA)}})),
+	}))
+	_, err := test.Program.Compile()
+	test.EqualError(err, "instances of `nest` are nested more than 64 deep")
+	test.checkCode(err)
+}
+
+func call(name code.Id, args ...code.Expr) code.Expr {
+	return code.ExprNew(code.Call{Func: code.ExprNew(code.Var{Name: name}), Args: args})
+}
+
+func mustInstance(test *Test, typ code.Type, args ...code.Type) code.Type {
+	out, err := test.Program.Types().Instance(typ, args...)
+	test.NoError(err)
+	return out
+}
@@ -0,0 +1,93 @@
+package code_tests
+
+import (
+	"testing"
+
+	"axlab.dev/bit/code"
+)
+
+func TestGlobals(t *testing.T) {
+	test := NewTest(t)
+	varA := code.Var{Name: "a"}
+	test.Program.Append(
+		code.ExprNew(code.Let{Decl: varA, Init: num(1)}),
+		code.ExprNew(code.Print{Args: []code.Expr{binary(code.OpAdd, code.ExprNew(varA), num(1))}}),
+	)
+	test.ExpectStdOut = "2\n"
+	test.Check()
+}
+
+func TestGlobalInitOrder(t *testing.T) {
+	test := NewTest(t)
+	types := test.Program.Types()
+	typeNum := types.Scalar(code.TypeScalarNumber)
+
+	varA := code.Var{Name: "a"}
+	varB := code.Var{Name: "b"}
+	initWith := func(name string, value code.Expr) code.Expr {
+		return code.ExprNew(code.Block{List: []code.Expr{
+			code.ExprNew(code.Print{Args: []code.Expr{str("init"), str(name)}}),
+			value,
+		}})
+	}
+
+	test.Program.Append(
+		code.ExprNew(code.Func{
+			Name:   "get_b",
+			Result: typeNum,
+			Body:   code.ExprNew(varB),
+		}),
+		code.ExprNew(code.Let{Decl: varA, Init: initWith("a", num(1))}),
+		code.ExprNew(code.Print{Args: []code.Expr{str("call"), call("get_b")}}),
+		code.ExprNew(code.Let{Decl: varB, Init: initWith("b", binary(code.OpAdd, code.ExprNew(varA), num(1)))}),
+		code.ExprNew(code.Print{Args: []code.Expr{code.ExprNew(varA), code.ExprNew(varB)}}),
+	)
+
+	test.ExpectStdOut = "init a\ninit b\ncall 2\n1 2\n"
+	test.Check()
+}
+
+func TestGlobalTypedForwardRef(t *testing.T) {
+	test := NewTest(t)
+	typeNum := test.Program.Types().Scalar(code.TypeScalarNumber)
+	test.Program.Append(
+		code.ExprNew(code.Print{Args: []code.Expr{code.ExprNew(code.Var{Name: "g", Type: typeNum})}}),
+		code.ExprNew(code.Let{Decl: code.Var{Name: "g"}, Init: num(7)}),
+	)
+	test.ExpectStdOut = "7\n"
+	test.Check()
+
+	test = NewTest(t)
+	typeStr := test.Program.Types().Scalar(code.TypeScalarString)
+	test.Program.Append(
+		code.ExprNew(code.Print{Args: []code.Expr{code.ExprNew(code.Var{Name: "g", Type: typeStr})}}),
+		code.ExprNew(code.Let{Decl: code.Var{Name: "g"}, Init: num(7)}),
+	)
+	test.CheckCompileError("variable `g` has type `Number`, not `String`")
+}
+
+func TestGlobalErrors(t *testing.T) {
+	test := NewTest(t)
+	varA := code.Var{Name: "a"}
+	varB := code.Var{Name: "b"}
+	test.Program.Append(
+		code.ExprNew(code.Let{Decl: varA, Init: code.ExprNew(varB)}),
+		code.ExprNew(code.Let{Decl: varB, Init: code.ExprNew(varA)}),
+	)
+	test.CheckCompileError("cyclic initialization of global `a`")
+
+	test = NewTest(t)
+	test.Program.Append(
+		code.ExprNew(code.Let{Decl: varA, Init: num(1)}),
+		code.ExprNew(code.Let{Decl: varA, Init: num(2)}),
+	)
+	test.CheckCompileError("`a` is already declared")
+
+	test = NewTest(t)
+	typeNum := test.Program.Types().Scalar(code.TypeScalarNumber)
+	test.Program.Append(
+		code.ExprNew(code.Func{Name: "get_a", Result: typeNum, Body: code.ExprNew(varA)}),
+		code.ExprNew(code.Let{Decl: varA, Init: call("get_a")}),
+	)
+	test.CheckRuntimeError("cyclic initialization of global `a`")
+}
@@ -0,0 +1,87 @@
+package code_tests
+
+import (
+	"math/big"
+	"testing"
+
+	"axlab.dev/bit/code"
+)
+
+func TestList(t *testing.T) {
+	test := NewTest(t)
+	program := &test.Program
+
+	varL := code.Var{Name: "l"}
+	program.Append(code.ExprNew(code.Block{
+		List: []code.Expr{
+			code.ExprNew(code.Let{Decl: varL, Init: numList(1, 2, 3)}),
+			method(code.ExprNew(varL), "push", num(4)),
+			code.ExprNew(code.Print{
+				Args: []code.Expr{
+					method(code.ExprNew(varL), "len"),
+					code.ExprNew(code.Index{Value: code.ExprNew(varL), Index: num(0)}),
+					code.ExprNew(code.Index{Value: code.ExprNew(varL), Index: num(3)}),
+					method(code.ExprNew(varL), "pop"),
+					method(code.ExprNew(varL), "len"),
+				},
+			}),
+			code.ExprNew(code.Slice{Value: code.ExprNew(varL), From: num(1)}),
+		},
+	}))
+
+	test.ExpectStdOut = "4 1 4 4 3\n"
+	test.ExpectResult = &code.ListValue{Items: []any{big.NewInt(2), big.NewInt(3)}}
+	test.Check()
+
+	test = NewTest(t)
+	test.Program.Append(code.ExprNew(code.Slice{Value: numList(1, 2, 3, 4), From: num(1), To: num(3)}))
+	test.ExpectResult = &code.ListValue{Items: []any{big.NewInt(2), big.NewInt(3)}}
+	test.Check()
+
+	test = NewTest(t)
+	test.Program.Append(code.ExprNew(code.List{Type: test.Program.Types().Scalar(code.TypeScalarString)}))
+	test.ExpectResult = &code.ListValue{Items: []any{}}
+	test.Check()
+}
+
+func TestListErrors(t *testing.T) {
+	test := NewTest(t)
+	test.Program.Append(code.ExprNew(code.Index{Value: numList(1, 2, 3), Index: num(3)}))
+	test.CheckRuntimeError("index out of bounds: index is 3 but length is 3")
+
+	test = NewTest(t)
+	test.Program.Append(code.ExprNew(code.Slice{Value: numList(1, 2, 3), From: num(2), To: num(1)}))
+	test.CheckRuntimeError("slice out of bounds: range is [2:1] but length is 3")
+
+	test = NewTest(t)
+	test.Program.Append(method(code.ExprNew(code.List{Type: test.Program.Types().Scalar(code.TypeScalarNumber)}), "pop"))
+	test.CheckRuntimeError("pop from empty list")
+
+	test = NewTest(t)
+	test.Program.Append(code.ExprNew(code.List{}))
+	test.CheckCompileError("empty list requires an element type")
+
+	test = NewTest(t)
+	test.Program.Append(code.ExprNew(code.List{Items: []code.Expr{num(1), code.ExprNew(code.Str{Value: "2"})}}))
+	test.CheckCompileError("list item 2: type mismatch: expected `Number`, got `String`")
+
+	test = NewTest(t)
+	test.Program.Append(method(numList(1), "push", code.ExprNew(code.Str{Value: "2"})))
+	test.CheckCompileError("type mismatch: expected `Number`, got `String`")
+
+	test = NewTest(t)
+	test.Program.Append(code.ExprNew(code.Index{Value: numList(1), Index: code.ExprNew(code.Str{Value: "0"})}))
+	test.CheckCompileError("index must be an integer, got `String`")
+}
+
+func num(value int64) code.Expr {
+	return code.ExprNew(code.Number{Value: value})
+}
+
+func numList(values ...int64) code.Expr {
+	items := make([]code.Expr, len(values))
+	for n, it := range values {
+		items[n] = num(it)
+	}
+	return code.ExprNew(code.List{Items: items})
+}
@@ -0,0 +1,122 @@
+package code_tests
+
+import (
+	"fmt"
+	"testing"
+
+	"axlab.dev/bit/code"
+)
+
+func TestStrEscapes(t *testing.T) {
+	test := NewTest(t)
+
+	expr, err := code.StrLiteral(`a\tb\nc \"q\" \\ \{x\} \u{48}\u{1F600}\0.`, false, nil)
+	test.NoError(err)
+	test.Equal(code.Str{Value: "a\tb\nc \"q\" \\ {x} H😀\x00."}, expr.Value())
+
+	expr, err = code.StrLiteral(`raw\n{x}`, true, nil)
+	test.NoError(err)
+	test.Equal(code.Str{Value: `raw\n{x}`}, expr.Value())
+
+	expr, err = code.StrLiteral(``, false, nil)
+	test.NoError(err)
+	test.Equal(code.Str{Value: ""}, expr.Value())
+}
+
+func TestStrInterpolation(t *testing.T) {
+	test := NewTest(t)
+
+	varX := code.Var{Name: "x"}
+	varS := code.Var{Name: "s"}
+	parse := parseWith(map[string]code.Expr{
+		"x + 1": binary(code.OpAdd, code.ExprNew(varX), num(1)),
+		"s":     code.ExprNew(varS),
+		"{s}":   code.ExprNew(code.Tuple{Items: []code.Expr{code.ExprNew(varS)}}),
+		`"}"`:   str("}"),
+	})
+
+	interp, err := code.StrLiteral(`x = {x + 1}, s = '{s}'{"}"}\n`, false, parse)
+	test.NoError(err)
+
+	test.Program.Append(code.ExprNew(code.Block{
+		List: []code.Expr{
+			code.ExprNew(code.Let{Decl: varX, Init: num(41)}),
+			code.ExprNew(code.Let{Decl: varS, Init: str("str")}),
+			interp,
+		},
+	}))
+
+	test.ExpectResult = "x = 42, s = 'str'}\n"
+	test.Check()
+}
+
+func TestTextLiteral(t *testing.T) {
+	test := NewTest(t)
+
+	expr, err := code.TextLiteral("\r\tL1\r\t\tL2\r\tL3", false, nil)
+	test.NoError(err)
+	test.Equal(code.Str{Value: "L1\n\tL2\nL3\n"}, expr.Value())
+
+	expr, err = code.TextLiteral(`
+		SELECT *
+		  FROM t\tx  
+		 WHERE a = 1
+	`, true, nil)
+	test.NoError(err)
+	test.Equal(code.Str{Value: "SELECT *\n  FROM t\\tx\n WHERE a = 1\n"}, expr.Value())
+
+	varN := code.Var{Name: "n"}
+	parse := parseWith(map[string]code.Expr{"n": code.ExprNew(varN)})
+	expr, err = code.TextLiteral(`
+		Usage:
+		    run {n} times\t(max)
+	`, false, parse)
+	test.NoError(err)
+
+	test.Program.Append(code.ExprNew(code.Block{
+		List: []code.Expr{
+			code.ExprNew(code.Let{Decl: varN, Init: num(3)}),
+			expr,
+		},
+	}))
+	test.ExpectResult = "Usage:\n    run 3 times\t(max)\n"
+	test.Check()
+}
+
+func TestStrLiteralErrors(t *testing.T) {
+	test := NewTest(t)
+	parse := parseWith(map[string]code.Expr{"x": code.ExprNew(code.Var{Name: "x"})})
+
+	for _, it := range []struct{ text, err string }{
+		{`a\q`, "invalid escape sequence `\\q` at offset 1 in string literal"},
+		{`a\`, "incomplete escape sequence at offset 1 in string literal"},
+		{`\u{110000}`, "invalid unicode escape `\\u{110000}` at offset 0 in string literal"},
+		{`\u0041`, "invalid unicode escape, expected `\\u{...}` at offset 0 in string literal"},
+		{`\u{0000041}`, "invalid unicode escape, expected `\\u{...}` at offset 0 in string literal"},
+		{`\u{41 and {x}`, "invalid unicode escape, expected `\\u{...}` at offset 0 in string literal"},
+		{`a {x`, "unclosed `{` at offset 2 in string literal"},
+		{`a }`, "unmatched `}` at offset 2 in string literal"},
+		{`a { }`, "empty interpolation at offset 2 in string literal"},
+		{`{y}`, "in interpolation at offset 0: cannot parse `y`"},
+	} {
+		_, err := code.StrLiteral(it.text, false, parse)
+		test.EqualError(err, it.err, "for %s", it.text)
+	}
+
+	_, err := code.StrLiteral(`{x}`, false, nil)
+	test.EqualError(err, "unescaped `{` at offset 0 in string literal")
+
+	interp, err := code.StrLiteral(`{x}`, false, parse)
+	test.NoError(err)
+	test.Program.Append(interp)
+	test.CheckCompileError("variable `x` not in the scope")
+}
+
+func parseWith(exprs map[string]code.Expr) code.ParseFunc {
+	return func(src string) (code.Expr, error) {
+		if expr, ok := exprs[src]; ok {
+			return expr, nil
+		}
+		return code.Expr{}, fmt.Errorf("cannot parse `%s`", src)
+	}
+}
@@ -0,0 +1,184 @@
+package code_tests
+
+import (
+	"math/big"
+	"testing"
+
+	"axlab.dev/bit/code"
+)
+
+func TestMap(t *testing.T) {
+	test := NewTest(t)
+	program := &test.Program
+
+	varM := code.Var{Name: "m"}
+	varK := code.Var{Name: "k"}
+	varV := code.Var{Name: "v"}
+
+	program.Append(code.ExprNew(code.Block{
+		List: []code.Expr{
+			code.ExprNew(code.Let{Decl: varM, Init: code.ExprNew(code.Map{
+				Entries: []code.MapEntry{
+					{Key: str("a"), Value: num(1)},
+					{Key: str("b"), Value: num(2)},
+				},
+			})}),
+			method(code.ExprNew(varM), "insert", str("c"), num(3)),
+			method(code.ExprNew(varM), "delete", str("a")),
+			method(code.ExprNew(varM), "insert", str("a"), num(4)),
+			method(code.ExprNew(varM), "insert", str("b"), num(5)),
+			code.ExprNew(code.For{
+				Vars:  []code.Var{varK, varV},
+				Value: code.ExprNew(varM),
+				Body:  code.ExprNew(code.Print{Args: []code.Expr{code.ExprNew(varK), code.ExprNew(varV)}}),
+			}),
+			code.ExprNew(code.Print{
+				Args: []code.Expr{
+					code.ExprNew(code.Index{Value: code.ExprNew(varM), Index: str("c")}),
+					method(code.ExprNew(varM), "has", str("a")),
+					method(code.ExprNew(varM), "has", str("x")),
+					method(code.ExprNew(varM), "delete", str("x")),
+					method(code.ExprNew(varM), "len"),
+				},
+			}),
+		},
+	}))
+
+	test.ExpectStdOut = "b 5\nc 3\na 4\n3 true false false 3\n"
+	test.Check()
+}
+
+func TestMapStructuralKeys(t *testing.T) {
+	test := NewTest(t)
+	program := &test.Program
+	types := program.Types()
+
+	typeNum := types.Scalar(code.TypeScalarNumber)
+	point := types.Record("Point")
+	test.NoError(point.Def().(code.TypeRecord).Define(
+		code.RecordField{Name: "x", Type: typeNum},
+		code.RecordField{Name: "y", Type: typeNum},
+	))
+
+	newPoint := func(x, y int64) code.Expr {
+		return code.ExprNew(code.Record{Type: point, Fields: []code.Expr{num(x), num(y)}})
+	}
+
+	tuple := func(n int64, s string) code.Expr {
+		return code.ExprNew(code.Tuple{Items: []code.Expr{num(n), str(s)}})
+	}
+
+	varP := code.Var{Name: "p"}
+	varT := code.Var{Name: "t"}
+	varK := code.Var{Name: "k"}
+
+	program.Append(code.ExprNew(code.Block{
+		List: []code.Expr{
+			code.ExprNew(code.Let{Decl: varP, Init: code.ExprNew(code.Map{
+				Entries: []code.MapEntry{
+					{Key: newPoint(1, 2), Value: str("a")},
+					{Key: newPoint(2, 1), Value: str("b")},
+				},
+			})}),
+			code.ExprNew(code.Let{Decl: varT, Init: code.ExprNew(code.Map{
+				Entries: []code.MapEntry{
+					{Key: tuple(1, "x"), Value: str("c")},
+				},
+			})}),
+			method(code.ExprNew(varT), "insert", tuple(1, "x"), str("d")),
+			method(code.ExprNew(varT), "insert", tuple(2, "x"), str("e")),
+			code.ExprNew(code.Print{
+				Args: []code.Expr{
+					code.ExprNew(code.Index{Value: code.ExprNew(varP), Index: newPoint(2, 1)}),
+					code.ExprNew(code.Index{Value: code.ExprNew(varT), Index: tuple(1, "x")}),
+					method(code.ExprNew(varT), "len"),
+				},
+			}),
+			code.ExprNew(code.For{
+				Vars:  []code.Var{varK},
+				Value: numList(1, 2, 3, 4, 5, 6, 7, 8, 9, 10),
+				Body:  method(code.ExprNew(varP), "insert", newPoint(0, 0), str("z")),
+			}),
+			code.ExprNew(code.For{
+				Vars:  []code.Var{varK},
+				Value: method(code.ExprNew(varP), "keys"),
+				Body:  code.ExprNew(code.Print{Args: []code.Expr{code.ExprNew(code.Index{Value: code.ExprNew(varP), Index: code.ExprNew(varK)})}}),
+			}),
+		},
+	}))
+
+	test.ExpectStdOut = "b d 2\na\nb\nz\n"
+	test.Check()
+}
+
+func TestMapCompaction(t *testing.T) {
+	test := NewTest(t)
+	program := &test.Program
+
+	varM := code.Var{Name: "m"}
+	varK := code.Var{Name: "k"}
+
+	entries := []code.MapEntry{}
+	for n := int64(1); n <= 12; n++ {
+		entries = append(entries, code.MapEntry{Key: num(n), Value: num(n * 10)})
+	}
+
+	program.Append(code.ExprNew(code.Block{
+		List: []code.Expr{
+			code.ExprNew(code.Let{Decl: varM, Init: code.ExprNew(code.Map{Entries: entries})}),
+			code.ExprNew(code.For{
+				Vars:  []code.Var{varK},
+				Value: numList(1, 2, 3, 5, 7, 8, 9, 11),
+				Body:  method(code.ExprNew(varM), "delete", code.ExprNew(varK)),
+			}),
+			method(code.ExprNew(varM), "insert", num(1), num(0)),
+			method(code.ExprNew(varM), "values"),
+		},
+	}))
+
+	test.ExpectResult = &code.ListValue{Items: []any{big.NewInt(40), big.NewInt(60), big.NewInt(100), big.NewInt(120), big.NewInt(0)}}
+	test.Check()
+}
+
+func TestMapErrors(t *testing.T) {
+	test := NewTest(t)
+	test.Program.Append(code.ExprNew(code.Map{
+		Entries: []code.MapEntry{{Key: numList(1), Value: num(1)}},
+	}))
+	test.CheckCompileError("type `List[Number]` cannot be used as a map key")
+
+	test = NewTest(t)
+	types := test.Program.Types()
+	typeNum := types.Scalar(code.TypeScalarNumber)
+	test.Program.Append(code.ExprNew(code.Block{
+		List: []code.Expr{
+			code.ExprNew(code.Func{
+				Name:   "f",
+				Params: []code.Var{{Name: "m", Type: types.Map(types.Map(typeNum, typeNum), typeNum)}},
+				Body:   num(0),
+			}),
+		},
+	}))
+	test.CheckCompileError("type `Map[Number, Number]` cannot be used as a map key")
+
+	test = NewTest(t)
+	test.Program.Append(code.ExprNew(code.Map{
+		Entries: []code.MapEntry{{Key: code.ExprNew(code.Float{Value: 0.5}), Value: num(1)}},
+	}))
+	test.CheckCompileError("type `Float` cannot be used as a map key")
+
+	test = NewTest(t)
+	test.Program.Append(code.ExprNew(code.Map{}))
+	test.CheckCompileError("empty map requires key and value types")
+
+	test = NewTest(t)
+	test.Program.Append(code.ExprNew(code.Index{
+		Value: code.ExprNew(code.Map{Entries: []code.MapEntry{{Key: str("a"), Value: num(1)}}}),
+		Index: str("b"),
+	}))
+	test.CheckRuntimeError("key not found in map")
+}
+
+func str(value string) code.Expr {
+	return code.ExprNew(code.Str{Value: value})
+}
@@ -0,0 +1,233 @@
+package code_tests
+
+import (
+	"testing"
+
+	"axlab.dev/bit/code"
+)
+
+func TestMethods(t *testing.T) {
+	test := NewTest(t)
+	program := &test.Program
+	types := program.Types()
+
+	typeNum := types.Scalar(code.TypeScalarNumber)
+	typeStr := types.Scalar(code.TypeScalarString)
+
+	point := types.Record("Point")
+	test.NoError(point.Def().(code.TypeRecord).Define(
+		code.RecordField{Name: "x", Type: typeNum},
+		code.RecordField{Name: "y", Type: typeNum},
+	))
+
+	typeA, typeB := types.Var("A"), types.Var("B")
+	pair := types.Record("Pair", typeA, typeB)
+	test.NoError(pair.Def().(code.TypeRecord).Define(
+		code.RecordField{Name: "fst", Type: typeA},
+		code.RecordField{Name: "snd", Type: typeB},
+	))
+
+	color := types.Enum("Color")
+	test.NoError(color.Def().(code.TypeEnum).Define(
+		code.EnumVariant{Name: "Red"},
+		code.EnumVariant{Name: "Green"},
+	))
+
+	show := declareShow(test)
+
+	selfPoint := code.Var{Name: "self", Type: point}
+	selfPair := code.Var{Name: "self", Type: pair}
+	selfColor := code.Var{Name: "self", Type: color}
+	selfNum := code.Var{Name: "self", Type: typeNum}
+	other := code.Var{Name: "other", Type: point}
+
+	program.Append(
+		code.ExprNew(code.Impl{
+			Type: point,
+			Methods: []code.Func{
+				{
+					Name:   "get_x",
+					Params: []code.Var{selfPoint},
+					Result: typeNum,
+					Body:   code.ExprNew(code.Field{Value: code.ExprNew(selfPoint), Name: "x"}),
+				},
+				{
+					Name:   "other_y",
+					Params: []code.Var{selfPoint, other},
+					Result: typeNum,
+					Body:   code.ExprNew(code.Field{Value: code.ExprNew(other), Name: "y"}),
+				},
+			},
+		}),
+		code.ExprNew(code.Impl{
+			Generics: []code.Type{typeA, typeB},
+			Type:     pair,
+			Methods: []code.Func{
+				{
+					Name:   "first",
+					Params: []code.Var{selfPair},
+					Result: typeA,
+					Body:   code.ExprNew(code.Field{Value: code.ExprNew(selfPair), Name: "fst"}),
+				},
+			},
+		}),
+		code.ExprNew(code.Impl{
+			Type: color,
+			Methods: []code.Func{
+				{
+					Name:   "name",
+					Params: []code.Var{selfColor},
+					Result: typeStr,
+					Body: code.ExprNew(code.Match{
+						Value: code.ExprNew(selfColor),
+						Cases: []code.MatchCase{
+							{Variant: "Red", Body: code.ExprNew(code.Str{Value: "red"})},
+							{Body: code.ExprNew(code.Str{Value: "other"})},
+						},
+					}),
+				},
+			},
+		}),
+		code.ExprNew(code.Impl{
+			Trait: show,
+			Type:  typeNum,
+			Methods: []code.Func{
+				{
+					Name:   "show",
+					Params: []code.Var{selfNum},
+					Result: typeStr,
+					Body:   code.ExprNew(code.Str{Value: "a number"}),
+				},
+			},
+		}),
+	)
+
+	varP := code.Var{Name: "p", Type: point}
+	varQ := code.Var{Name: "q", Type: point}
+	newPoint := func(x, y int64) code.Expr {
+		return code.ExprNew(code.Record{
+			Type:   point,
+			Fields: []code.Expr{code.ExprNew(code.Number{Value: x}), code.ExprNew(code.Number{Value: y})},
+		})
+	}
+
+	program.Append(code.ExprNew(code.Block{
+		List: []code.Expr{
+			code.ExprNew(code.Let{Decl: varP, Init: newPoint(1, 2)}),
+			code.ExprNew(code.Let{Decl: varQ, Init: newPoint(3, 4)}),
+			code.ExprNew(code.Print{
+				Args: []code.Expr{
+					method(code.ExprNew(code.Str{Value: "aé☃"}), "len"),
+					method(code.ExprNew(varP), "get_x"),
+					method(code.ExprNew(varP), "other_y", code.ExprNew(varQ)),
+					method(code.ExprNew(code.Record{
+						Type:   pair,
+						Fields: []code.Expr{code.ExprNew(code.Str{Value: "fst"}), code.ExprNew(code.Number{Value: 0})},
+					}), "first"),
+					method(code.ExprNew(code.Variant{Type: color, Name: "Red"}), "name"),
+					method(code.ExprNew(code.Variant{Type: color, Name: "Green"}), "name"),
+					method(code.ExprNew(code.Number{Value: 7}), "show"),
+				},
+			}),
+		},
+	}))
+
+	test.ExpectStdOut = "3 1 4 fst red other a number\n"
+	test.Check()
+}
+
+func TestMethodErrors(t *testing.T) {
+	test := NewTest(t)
+	test.Program.Append(method(code.ExprNew(code.Str{Value: "abc"}), "size"))
+	test.CheckCompileError("type `String` has no method `size`")
+
+	test = NewTest(t)
+	types := test.Program.Types()
+	typeStr := types.Scalar(code.TypeScalarString)
+	self := code.Var{Name: "self", Type: typeStr}
+	test.Program.Append(code.ExprNew(code.Impl{
+		Type: typeStr,
+		Methods: []code.Func{
+			{Name: "len", Params: []code.Var{self}, Body: code.ExprNew(self)},
+		},
+	}))
+	test.CheckCompileError("duplicated method `len` for `String`")
+
+	test = NewTest(t)
+	types = test.Program.Types()
+	typeStr = types.Scalar(code.TypeScalarString)
+	test.Program.Append(code.ExprNew(code.Impl{
+		Type: typeStr,
+		Methods: []code.Func{
+			{Name: "bad", Body: code.ExprNew(code.Str{})},
+		},
+	}))
+	test.CheckCompileError("method `bad` for `String` must take the receiver as first parameter")
+
+	// methods only apply to the instances matching their impl
+	test = NewTest(t)
+	pair := newPair(test)
+	implMethod(test, mustInstance(test, pair, numberType(test), numberType(test)), nil, "f", str("numbers"))
+	test.Program.Append(method(newPairOf(pair, str("a"), str("b")), "f"))
+	test.CheckCompileError("type `Pair[String, String]` has no method `f`")
+
+	test = NewTest(t)
+	pair = newPair(test)
+	typeT := test.Program.Types().Var("T")
+	implMethod(test, mustInstance(test, pair, numberType(test), numberType(test)), nil, "f", str("numbers"))
+	implMethod(test, mustInstance(test, pair, typeT, typeT), []code.Type{typeT}, "f", str("any"))
+	test.CheckCompileError("duplicated method `f` for `Pair[T, T]`")
+}
+
+func TestInstanceMethods(t *testing.T) {
+	test := NewTest(t)
+	pair := newPair(test)
+	typeStr := test.Program.Types().Scalar(code.TypeScalarString)
+	implMethod(test, mustInstance(test, pair, numberType(test), numberType(test)), nil, "f", str("numbers"))
+	implMethod(test, mustInstance(test, pair, typeStr, typeStr), nil, "f", str("strings"))
+	test.Program.Append(code.ExprNew(code.Print{Args: []code.Expr{
+		method(newPairOf(pair, num(1), num(2)), "f"),
+		method(newPairOf(pair, str("a"), str("b")), "f"),
+	}}))
+	test.ExpectStdOut = "numbers strings\n"
+	test.Check()
+}
+
+// Declares the generic `Pair[A, B]` record.
+func newPair(test *Test) code.Type {
+	types := test.Program.Types()
+	typeA, typeB := types.Var("A"), types.Var("B")
+	pair := types.Record("Pair", typeA, typeB)
+	test.NoError(pair.Def().(code.TypeRecord).Define(
+		code.RecordField{Name: "fst", Type: typeA},
+		code.RecordField{Name: "snd", Type: typeB},
+	))
+	return pair
+}
+
+func newPairOf(pair code.Type, fst, snd code.Expr) code.Expr {
+	return code.ExprNew(code.Record{Type: pair, Fields: []code.Expr{fst, snd}})
+}
+
+// Declares a method for the type returning a constant string.
+func implMethod(test *Test, typ code.Type, generics []code.Type, name code.Id, result code.Expr) {
+	self := code.Var{Name: "_self", Type: typ}
+	test.Program.Append(code.ExprNew(code.Impl{
+		Generics: generics,
+		Type:     typ,
+		Methods: []code.Func{{
+			Name:   name,
+			Params: []code.Var{self},
+			Result: test.Program.Types().Scalar(code.TypeScalarString),
+			Body:   result,
+		}},
+	}))
+}
+
+func numberType(test *Test) code.Type {
+	return test.Program.Types().Scalar(code.TypeScalarNumber)
+}
+
+func method(value code.Expr, name code.Id, args ...code.Expr) code.Expr {
+	return code.ExprNew(code.MethodCall{Value: value, Method: name, Args: args})
+}
@@ -0,0 +1,167 @@
+package code_tests
+
+import (
+	"testing"
+
+	"axlab.dev/bit/base"
+	"axlab.dev/bit/code"
+)
+
+func TestModuleImports(t *testing.T) {
+	test := NewTest(t)
+	program := &test.Program
+	types := program.Types()
+	typeNum := types.Scalar(code.TypeScalarNumber)
+
+	typeT := types.Var("T")
+	argX := code.Var{Name: "x", Type: typeT}
+	argN := code.Var{Name: "n", Type: typeNum}
+
+	text := mustModule(test, "util/text")
+	text.Append(
+		code.ExprNew(code.Print{Args: []code.Expr{str("init text")}}),
+		code.ExprNew(code.Func{
+			Name:     "id",
+			Generics: []code.Type{typeT},
+			Params:   []code.Var{argX},
+			Result:   typeT,
+			Body:     code.ExprNew(argX),
+			Pub:      true,
+		}),
+	)
+
+	math := mustModule(test, "util/math")
+	math.Append(
+		code.ExprNew(code.Import{Path: "./text", Name: "txt"}),
+		code.ExprNew(code.Print{Args: []code.Expr{str("init math")}}),
+		code.ExprNew(code.Let{Decl: code.Var{Name: "base"}, Init: num(40), Pub: true}),
+		code.ExprNew(code.Func{
+			Name:   "add",
+			Params: []code.Var{argN},
+			Result: typeNum,
+			Body:   binary(code.OpAdd, qualified("txt", "id", code.ExprNew(argN)), code.ExprNew(code.Var{Name: "base"})),
+			Pub:    true,
+		}),
+	)
+
+	program.Append(
+		code.ExprNew(code.Print{Args: []code.Expr{qualified("math", "add", num(2))}}),
+		code.ExprNew(code.Import{Path: "util/math"}),
+		code.ExprNew(code.Import{Path: "util/text"}),
+		code.ExprNew(code.Print{Args: []code.Expr{
+			code.ExprNew(code.Qualified{Module: "math", Name: "base"}),
+			qualified("text", "id", str("abc")),
+		}}),
+	)
+
+	test.ExpectStdOut = "init text\ninit math\n42\n40 abc\n"
+	test.Check()
+}
+
+func TestModulePrivate(t *testing.T) {
+	test := NewTest(t)
+	first := base.Span{File: "util.bit", Sta: base.Pos{Line: 1, Column: 1}}
+
+	mustModule(test, "util").Append(
+		code.ExprAt(first, code.Let{Decl: code.Var{Name: "secret"}, Init: num(1)}),
+	)
+	test.Program.Append(
+		code.ExprNew(code.Import{Path: "util"}),
+		code.ExprNew(code.Print{Args: []code.Expr{code.ExprNew(code.Qualified{Module: "util", Name: "secret"})}}),
+	)
+
+	_, err := test.Program.Compile()
+	test.ErrorContains(err, "`secret` is private to module `util`")
+	test.checkCode(err)
+
+	diag := base.AsDiagnostic(err)
+	test.Len(diag.Related, 1)
+	test.Equal(first, diag.Related[0].Span)
+}
+
+func TestModuleErrors(t *testing.T) {
+	run := func(t *testing.T, msg string, setup func(test *Test)) {
+		test := NewTest(t)
+		setup(test)
+		test.CheckCompileError(msg)
+	}
+
+	run(t, "module `util` not found", func(test *Test) {
+		test.Program.Append(code.ExprNew(code.Import{Path: "util"}))
+	})
+
+	run(t, "invalid module path `../util`", func(test *Test) {
+		test.Program.Append(code.ExprNew(code.Import{Path: "../util"}))
+	})
+
+	run(t, "module `util` is already imported", func(test *Test) {
+		mustModule(test, "util")
+		test.Program.Append(
+			code.ExprNew(code.Import{Path: "util"}),
+			code.ExprNew(code.Import{Path: "./util"}),
+		)
+	})
+
+	run(t, "import of `util` must be at the top level of a module", func(test *Test) {
+		mustModule(test, "util")
+		test.Program.Append(code.ExprNew(code.Block{List: []code.Expr{
+			code.ExprNew(code.Import{Path: "util"}),
+		}}))
+	})
+
+	run(t, "module `other` is not imported", func(test *Test) {
+		test.Program.Append(code.ExprNew(code.Qualified{Module: "other", Name: "x"}))
+	})
+
+	run(t, "`x` not found in module `util`", func(test *Test) {
+		mustModule(test, "util")
+		test.Program.Append(
+			code.ExprNew(code.Import{Path: "util"}),
+			code.ExprNew(code.Qualified{Module: "util", Name: "x"}),
+		)
+	})
+
+	test := NewTest(t)
+	_, err := test.Program.Module("/abs")
+	test.ErrorContains(err, "invalid module path `/abs`")
+}
+
+func TestModuleNotFoundFile(t *testing.T) {
+	test := NewTest(t)
+	test.Program.Root = "/project"
+	test.Program.Append(code.ExprNew(code.Import{Path: "lib/util"}))
+	test.CheckCompileError("module `lib/util` not found, expected file `/project/lib/util.bit`")
+}
+
+func TestModuleImportCycle(t *testing.T) {
+	test := NewTest(t)
+	span := func(file string) base.Span {
+		return base.Span{File: file, Sta: base.Pos{Line: 1, Column: 1}}
+	}
+
+	mustModule(test, "a").Append(code.ExprAt(span("a.bit"), code.Import{Path: "b"}))
+	mustModule(test, "b").Append(code.ExprAt(span("b.bit"), code.Import{Path: "c"}))
+	mustModule(test, "c").Append(code.ExprAt(span("c.bit"), code.Import{Path: "a"}))
+	test.Program.Append(code.ExprNew(code.Import{Path: "a"}))
+
+	_, err := test.Program.Compile()
+	test.ErrorContains(err, "import cycle: `a` -> `b` -> `c` -> `a`")
+	test.checkCode(err)
+
+	diag := base.AsDiagnostic(err)
+	test.Equal(span("c.bit"), diag.Span)
+	test.Equal([]base.Related{
+		{Span: span("a.bit"), Message: "`a` imports `b`"},
+		{Span: span("b.bit"), Message: "`b` imports `c`"},
+	}, diag.Related)
+}
+
+func mustModule(test *Test, path string) *code.Module {
+	module, err := test.Program.Module(path)
+	test.NoError(err)
+	return module
+}
+
+func qualified(module, name code.Id, args ...code.Expr) code.Expr {
+	return code.ExprNew(code.Call{Func: code.ExprNew(code.Qualified{Module: module, Name: name}), Args: args})
+}
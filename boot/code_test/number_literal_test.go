@@ -0,0 +1,71 @@
+package code_tests
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"axlab.dev/bit/base"
+	"axlab.dev/bit/code"
+)
+
+func TestNumberLiterals(t *testing.T) {
+	test := NewTest(t)
+
+	parse := func(text string) code.Expr {
+		expr, err := code.NumberLiteral(base.Span{}, text)
+		test.NoError(err, "parsing %s", text)
+		return expr
+	}
+
+	big, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	test.Equal(code.Integer{Value: big}, parse("123_456_789_012_345_678_901_234_567_890").Value())
+	test.Equal(code.Float{Value: 3}, parse("3.0f").Value())
+	test.Equal(code.Float{Value: 10}, parse("10f").Value())
+	test.Equal(code.Float{Value: 1.5e-3}, parse("1.5e-3").Value())
+
+	test.Program.Append(code.ExprNew(code.Print{Args: []code.Expr{
+		parse("0xff"),
+		parse("0xFF_FFu16"),
+		parse("0o17"),
+		parse("0b1010_1010u8"),
+		parse("10u8"),
+		parse("1e3"),
+		parse("1_000i32"),
+		parse("0x7fff_ffff_ffff_ffff_ffff"),
+		parse("0.25"),
+	}}))
+
+	test.ExpectStdOut = "255 65535 15 170 10 1000.0 1000 604462909807314587353087 0.25\n"
+	test.Check()
+}
+
+func TestNumberLiteralErrors(t *testing.T) {
+	test := NewTest(t)
+	span := base.Span{File: "main.bit", Sta: base.Pos{Line: 2, Column: 7}}
+
+	for _, it := range []struct{ text, err string }{
+		{"0b102", "invalid digit `2` in binary literal `0b102`"},
+		{"0o8", "invalid digit `8` in octal literal `0o8`"},
+		{"1__0", "invalid `_` separator in numeric literal `1__0`"},
+		{"10_", "invalid `_` separator in numeric literal `10_`"},
+		{"10x", "invalid suffix `x` for integer literal `10x`"},
+		{"1.5u8", "invalid suffix `u8` for float literal `1.5u8`"},
+		{"0x", "invalid suffix `x` for integer literal `0x`"},
+		{"1.2.3", "invalid float literal `1.2.3`"},
+	} {
+		_, err := code.NumberLiteral(span, it.text)
+		test.EqualError(err, "main.bit:2:7: "+it.err, "for %s", it.text)
+	}
+
+	expr, err := code.NumberLiteral(span, "256u8")
+	test.NoError(err)
+	test.Program.Append(code.ExprNew(code.Print{Args: []code.Expr{expr}}))
+
+	_, err = test.Program.Compile()
+	test.EqualError(err, "main.bit:2:7: integer literal `256` out of range for `u8`")
+
+	var compileErr *code.CompileError
+	test.True(errors.As(err, &compileErr))
+	test.Equal(span, compileErr.Span)
+}
@@ -0,0 +1,98 @@
+package code_tests
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"axlab.dev/bit/code"
+)
+
+func TestNumberArbitraryPrecision(t *testing.T) {
+	test := NewTest(t)
+
+	varN := code.Var{Name: "n"}
+	test.Program.Append(code.ExprNew(code.Block{
+		List: []code.Expr{
+			code.ExprNew(code.Let{Decl: varN, Init: binary(code.OpMul, num(math.MaxInt64), num(math.MaxInt64))}),
+			code.ExprNew(code.Print{Args: []code.Expr{
+				code.ExprNew(varN),
+				binary(code.OpDiv, code.ExprNew(varN), num(math.MaxInt64)),
+				binary(code.OpRem, num(-7), num(2)),
+				binary(code.OpSub, num(math.MinInt64), num(1)),
+			}}),
+		},
+	}))
+
+	test.ExpectStdOut = "85070591730234615847396907784232501249 9223372036854775807 -1 -9223372036854775809\n"
+	test.Check()
+}
+
+func TestIntConversion(t *testing.T) {
+	test := NewTest(t)
+	types := test.Program.Types()
+	typeNum := types.Scalar(code.TypeScalarNumber)
+
+	argA := code.Var{Name: "a", Type: typeNum}
+	test.Program.Append(code.ExprNew(code.Block{
+		List: []code.Expr{
+			code.ExprNew(code.Func{Name: "twice", Params: []code.Var{argA}, Result: typeNum, Body: binary(code.OpAdd, code.ExprNew(argA), code.ExprNew(argA))}),
+			code.ExprNew(code.Print{Args: []code.Expr{
+				binary(code.OpAdd, toInt(test, num(40)), toInt(test, num(2))),
+				call("twice", toInt(test, num(math.MaxInt64))),
+				binary(code.OpMul, toInt(test, num(math.MaxInt64)), num(2)),
+			}}),
+		},
+	}))
+
+	test.ExpectStdOut = "42 18446744073709551614 18446744073709551614\n"
+	test.Check()
+
+	test = NewTest(t)
+	test.Program.Append(binary(code.OpAdd, toInt(test, num(40)), toInt(test, num(2))))
+	test.ExpectResult = int64(42)
+	test.Check()
+
+	test = NewTest(t)
+	test.Program.Append(code.ExprNew(code.Convert{Value: toInt(test, num(7)), Type: test.Program.Types().Scalar(code.TypeScalarNumber)}))
+	test.ExpectResult = big.NewInt(7)
+	test.Check()
+}
+
+func TestNumberErrors(t *testing.T) {
+	test := NewTest(t)
+	test.Program.Append(binary(code.OpAdd, toInt(test, num(math.MaxInt64)), toInt(test, num(1))))
+	test.CheckRuntimeError("integer overflow: `9223372036854775807 + 1` does not fit in `Int`")
+
+	test = NewTest(t)
+	test.Program.Append(binary(code.OpMul, toInt(test, num(math.MinInt64)), toInt(test, num(-1))))
+	test.CheckRuntimeError("integer overflow: `-9223372036854775808 * -1` does not fit in `Int`")
+
+	test = NewTest(t)
+	test.Program.Append(toInt(test, binary(code.OpAdd, num(math.MaxInt64), num(1))))
+	test.CheckRuntimeError("integer overflow: `9223372036854775808` does not fit in `Int`")
+
+	test = NewTest(t)
+	test.Program.Append(binary(code.OpDiv, num(1), num(0)))
+	test.CheckRuntimeError("division by zero")
+
+	test = NewTest(t)
+	test.Program.Append(binary(code.OpRem, toInt(test, num(1)), toInt(test, num(0))))
+	test.CheckRuntimeError("division by zero")
+
+	test = NewTest(t)
+	test.Program.Append(binary(code.OpAdd, num(1), str("x")))
+	test.CheckCompileError("operator `+` is not defined for `Number` and `String`")
+
+	test = NewTest(t)
+	test.Program.Append(toInt(test, str("x")))
+	test.CheckCompileError("cannot convert `String` to `Int`")
+}
+
+func binary(op code.BinaryOp, lhs, rhs code.Expr) code.Expr {
+	return code.ExprNew(code.Binary{Op: op, Lhs: lhs, Rhs: rhs})
+}
+
+func toInt(test *Test, expr code.Expr) code.Expr {
+	return code.ExprNew(code.Convert{Value: expr, Type: test.Program.Types().Scalar(code.TypeScalarInt)})
+}
@@ -0,0 +1,178 @@
+package code_tests
+
+import (
+	"math/big"
+	"strconv"
+	"testing"
+
+	"axlab.dev/bit/code"
+)
+
+func TestResultTry(t *testing.T) {
+	test := NewTest(t)
+	program := &test.Program
+	types := program.Types()
+
+	typeNum := types.Scalar(code.TypeScalarNumber)
+	typeStr := types.Scalar(code.TypeScalarString)
+	typePair := types.Tuple(typeNum, typeNum)
+	typeResult := types.Result(typePair, types.Error())
+
+	parse := code.ExprNew(&code.Native{
+		Name: "parse",
+		Type: types.Func(types.Result(typeNum, types.Error()), typeStr),
+		Eval: func(rt *code.Runtime, args []any) (out any, err error) {
+			n, err := strconv.ParseInt(args[0].(string), 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			return big.NewInt(n), nil
+		},
+	})
+
+	argA := code.Var{Name: "a", Type: typeStr}
+	argB := code.Var{Name: "b", Type: typeStr}
+	argR := code.Var{Name: "r", Type: typeResult}
+	bindV := code.Var{Name: "v"}
+	bindE := code.Var{Name: "e"}
+
+	try := func(arg code.Var) code.Expr {
+		return code.ExprNew(code.Try{
+			Value: code.ExprNew(code.Call{Func: parse, Args: []code.Expr{code.ExprNew(arg)}}),
+		})
+	}
+
+	program.Append(code.ExprNew(code.Block{
+		List: []code.Expr{
+			code.ExprNew(code.Func{
+				Name:   "pair",
+				Params: []code.Var{argA, argB},
+				Result: typeResult,
+				Body: code.ExprNew(code.Variant{
+					Type:  typeResult,
+					Name:  "Ok",
+					Value: code.ExprNew(code.Tuple{Items: []code.Expr{try(argA), try(argB)}}),
+				}),
+			}),
+			code.ExprNew(code.Func{
+				Name:   "show",
+				Params: []code.Var{argR},
+				Body: code.ExprNew(code.Match{
+					Value: code.ExprNew(argR),
+					Cases: []code.MatchCase{
+						{Variant: "Ok", Bind: bindV, Body: stmt(code.Print{Args: []code.Expr{str("ok"), code.ExprNew(bindV)}})},
+						{Variant: "Err", Bind: bindE, Body: stmt(code.Print{Args: []code.Expr{
+							str("error:"),
+							code.ExprNew(code.Field{Value: code.ExprNew(bindE), Name: "message"}),
+						}})},
+					},
+				}),
+			}),
+			call("show", call("pair", str("1"), str("2"))),
+			call("show", call("pair", str("1"), str("x"))),
+		},
+	}))
+
+	test.ExpectStdOut = "ok (1, 2)\nerror: strconv.ParseInt: parsing \"x\": invalid syntax\n"
+	test.Check()
+}
+
+func TestOptionTry(t *testing.T) {
+	test := NewTest(t)
+	program := &test.Program
+	types := program.Types()
+
+	typeNum := types.Scalar(code.TypeScalarNumber)
+	typeStr := types.Scalar(code.TypeScalarString)
+	typeOption := types.Option(typeNum)
+
+	argM := code.Var{Name: "m", Type: types.Map(typeStr, typeNum)}
+	argK := code.Var{Name: "k", Type: typeStr}
+	bindV := code.Var{Name: "v"}
+	varM := code.Var{Name: "m"}
+
+	lookup := func(key string) code.Expr {
+		return code.ExprNew(code.Match{
+			Value: call("lookup", code.ExprNew(varM), str(key)),
+			Cases: []code.MatchCase{
+				{Variant: "Some", Bind: bindV, Body: stmt(code.Print{Args: []code.Expr{code.ExprNew(bindV)}})},
+				{Variant: "None", Body: stmt(code.Print{Args: []code.Expr{str("none")}})},
+			},
+		})
+	}
+
+	program.Append(code.ExprNew(code.Block{
+		List: []code.Expr{
+			code.ExprNew(code.Func{
+				Name:   "lookup",
+				Params: []code.Var{argM, argK},
+				Result: typeOption,
+				Body: code.ExprNew(code.Variant{
+					Type:  typeOption,
+					Name:  "Some",
+					Value: code.ExprNew(code.Try{Value: method(code.ExprNew(argM), "get", code.ExprNew(argK))}),
+				}),
+			}),
+			code.ExprNew(code.Let{Decl: varM, Init: code.ExprNew(code.Map{
+				Entries: []code.MapEntry{{Key: str("a"), Value: num(1)}},
+			})}),
+			lookup("a"),
+			lookup("b"),
+		},
+	}))
+
+	test.ExpectStdOut = "1\nnone\n"
+	test.Check()
+}
+
+func TestTryErrors(t *testing.T) {
+	test := NewTest(t)
+	types := test.Program.Types()
+	typeNum := types.Scalar(code.TypeScalarNumber)
+	some := code.ExprNew(code.Variant{Type: types.Option(typeNum), Name: "Some", Value: num(1)})
+	test.Program.Append(code.ExprNew(code.Try{Value: some}))
+	test.CheckCompileError("`?` can only be used inside a function")
+
+	test = NewTest(t)
+	types = test.Program.Types()
+	typeNum = types.Scalar(code.TypeScalarNumber)
+	some = code.ExprNew(code.Variant{Type: types.Option(typeNum), Name: "Some", Value: num(1)})
+	test.Program.Append(code.ExprNew(code.Func{
+		Name:   "f",
+		Result: types.Result(typeNum, types.Error()),
+		Body: code.ExprNew(code.Variant{
+			Type:  types.Result(typeNum, types.Error()),
+			Name:  "Ok",
+			Value: code.ExprNew(code.Try{Value: some}),
+		}),
+	}))
+	test.CheckCompileError("`?` on `Option[Number]` requires function `f` to return an Option, not `Result[Number, Error]`")
+
+	test = NewTest(t)
+	types = test.Program.Types()
+	typeNum = types.Scalar(code.TypeScalarNumber)
+	typeStr := types.Scalar(code.TypeScalarString)
+	ok := code.ExprNew(code.Variant{Type: types.Result(typeNum, typeStr), Name: "Ok", Value: num(1)})
+	test.Program.Append(code.ExprNew(code.Func{
+		Name:   "g",
+		Result: types.Result(typeNum, types.Error()),
+		Body: code.ExprNew(code.Variant{
+			Type:  types.Result(typeNum, types.Error()),
+			Name:  "Ok",
+			Value: code.ExprNew(code.Try{Value: ok}),
+		}),
+	}))
+	test.CheckCompileError("`?` on `Result[Number, String]` has error type `String`, but function `g` returns `Result[Number, Error]`")
+
+	test = NewTest(t)
+	test.Program.Append(code.ExprNew(code.Func{
+		Name: "h",
+		Body: code.ExprNew(code.Try{Value: num(1)}),
+	}))
+	test.CheckCompileError("`?` requires an Option or Result value, got `Number`")
+}
+
+// Evaluates the expression discarding its value.
+func stmt(expr code.ExprValue) code.Expr {
+	return code.ExprNew(code.Block{List: []code.Expr{code.ExprNew(expr), code.ExprNew(code.Tuple{})}})
+}
@@ -0,0 +1,89 @@
+package code_tests
+
+import (
+	"testing"
+
+	"axlab.dev/bit/base"
+	"axlab.dev/bit/code"
+)
+
+func TestFormat(t *testing.T) {
+	test := NewTest(t)
+
+	float := func(text string) code.Expr {
+		expr, err := code.NumberLiteral(base.Span{}, text)
+		test.NoError(err)
+		return expr
+	}
+
+	format := func(fmt string, args ...code.Expr) code.Expr {
+		return code.ExprNew(code.Format{Format: str(fmt), Args: args})
+	}
+
+	test.Program.Append(code.ExprNew(code.Block{
+		List: []code.Expr{
+			code.ExprNew(code.Format{Print: true, Format: str("%-6s|%6s|%^7s|\n"), Args: []code.Expr{str("ab"), str("cd"), str("mid")}}),
+			code.ExprNew(code.Format{Print: true, Format: str("%05d %+d %x %#X %#o %#b %08b\n"), Args: []code.Expr{
+				num(-42), num(7), num(255), num(255), num(8), num(5), as(test, code.TypeScalarU8, num(5)),
+			}}),
+			code.ExprNew(code.Format{Print: true, Format: str("%.2f|%8.3f|%-8.1e|%g|%.3s|%c|%x\n"), Args: []code.Expr{
+				float("3.14159"), float("2.5"), float("1234.5"), float("0.1"), str("héllo"), code.ExprNew(code.Char{Value: 'λ'}), code.ExprNew(code.Bytes{Value: []byte{0xca, 0xfe}}),
+			}}),
+			format("%v = %5v%%", code.ExprNew(code.Tuple{Items: []code.Expr{num(1), str("a")}}), num(12)),
+		},
+	}))
+
+	test.ExpectStdOut = "ab    |    cd|  mid  |\n-0042 +7 ff 0xFF 0o10 0b101 00000101\n3.14|   2.500|1.2e+03 |0.1|hél|λ|cafe\n"
+	test.ExpectResult = `(1, "a") =    12%`
+	test.Check()
+}
+
+func TestFormatDynamic(t *testing.T) {
+	test := NewTest(t)
+
+	varF := code.Var{Name: "f"}
+	test.Program.Append(code.ExprNew(code.Block{
+		List: []code.Expr{
+			code.ExprNew(code.Let{Decl: varF, Init: str("[%4d]")}),
+			code.ExprNew(code.Format{Format: code.ExprNew(varF), Args: []code.Expr{num(7)}}),
+		},
+	}))
+	test.ExpectResult = "[   7]"
+	test.Check()
+
+	test = NewTest(t)
+	test.Program.Append(code.ExprNew(code.Block{
+		List: []code.Expr{
+			code.ExprNew(code.Let{Decl: varF, Init: str("%d")}),
+			code.ExprNew(code.Format{Format: code.ExprNew(varF), Args: []code.Expr{str("x")}}),
+		},
+	}))
+	test.CheckRuntimeError("format `%d` does not accept `String` for argument 1")
+}
+
+func TestFormatErrors(t *testing.T) {
+	for _, it := range []struct {
+		format string
+		args   func(test *Test) []code.Expr
+		err    string
+	}{
+		{"%d", func(test *Test) []code.Expr { return []code.Expr{str("x")} }, "format `%d` does not accept `String` for argument 1"},
+		{"%s %s", func(test *Test) []code.Expr { return []code.Expr{str("a"), num(1)} }, "format `%s` does not accept `Number` for argument 2"},
+		{"%.2d", func(test *Test) []code.Expr { return []code.Expr{num(1)} }, "format `%.2d` does not accept a precision"},
+		{"%d %d", func(test *Test) []code.Expr { return []code.Expr{num(1)} }, "format has 2 specifiers, but 1 arguments were given"},
+		{"%d", func(test *Test) []code.Expr { return nil }, "format has 1 specifiers, but 0 arguments were given"},
+		{"%k", func(test *Test) []code.Expr { return nil }, "invalid format verb `k` in `%k`"},
+		{"abc %-5", func(test *Test) []code.Expr { return nil }, "incomplete format specifier `%-5`"},
+		{"%1001d", func(test *Test) []code.Expr { return []code.Expr{num(1)} }, "width and precision in `%1001` cannot exceed 1000"},
+		{"%5.99999999999999999999f", func(test *Test) []code.Expr { return nil }, "width and precision in `%5.99999999999999999999` cannot exceed 1000"},
+		{"%f", func(test *Test) []code.Expr { return []code.Expr{num(1)} }, "format `%f` does not accept `Number` for argument 1"},
+	} {
+		test := NewTest(t)
+		test.Program.Append(code.ExprNew(code.Format{Format: str(it.format), Args: it.args(test)}))
+		test.CheckCompileError(it.err)
+	}
+
+	test := NewTest(t)
+	test.Program.Append(code.ExprNew(code.Format{Format: num(1)}))
+	test.CheckCompileError("format must be a `String`, got `Number`")
+}
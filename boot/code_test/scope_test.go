@@ -0,0 +1,176 @@
+package code_tests
+
+import (
+	"testing"
+
+	"axlab.dev/bit/base"
+	"axlab.dev/bit/code"
+)
+
+func TestRedeclaration(t *testing.T) {
+	at := func(line, column int) base.Span {
+		return base.Span{File: "main.bit", Sta: base.Pos{Line: line, Column: column}}
+	}
+
+	varX := code.Var{Name: "x"}
+	test := NewTest(t)
+	test.Program.Append(code.ExprNew(code.Block{List: []code.Expr{
+		code.ExprAt(at(1, 5), code.Let{Decl: varX, Init: num(1)}),
+		code.ExprAt(at(2, 5), code.Let{Decl: varX, Init: num(2)}),
+	}}))
+	test.CheckCompileError("main.bit:2:5: `x` is already declared\n    main.bit:1:5: first declared here")
+
+	test = NewTest(t)
+	typeNum := test.Program.Types().Scalar(code.TypeScalarNumber)
+	test.Program.Append(code.ExprAt(at(3, 1), code.Func{
+		Name:   "f",
+		Params: []code.Var{{Name: "a", Type: typeNum}, {Name: "a", Type: typeNum}},
+		Body:   num(1),
+	}))
+	test.CheckCompileError("`a` is already declared\n    main.bit:3:1: first declared here")
+
+	test = NewTest(t)
+	test.Program.Append(code.ExprAt(at(3, 1), code.Func{
+		Name: "f",
+		Params: []code.Var{
+			{Name: "a", Type: typeNum, Span: at(3, 7)},
+			{Name: "a", Type: typeNum, Span: at(3, 18)},
+		},
+		Body: num(1),
+	}))
+	test.CheckCompileError("main.bit:3:18: `a` is already declared\n    main.bit:3:7: first declared here")
+
+	test = NewTest(t)
+	test.Program.Append(
+		code.ExprAt(at(1, 1), code.Let{Decl: varX, Init: num(1)}),
+		code.ExprAt(at(2, 1), code.Func{Name: "x", Body: num(1)}),
+	)
+	test.CheckCompileError("main.bit:2:1: `x` is already declared\n    main.bit:1:1: first declared here")
+}
+
+func TestShadowWarning(t *testing.T) {
+	at := func(line, column int) base.Span {
+		return base.Span{File: "main.bit", Sta: base.Pos{Line: line, Column: column}}
+	}
+
+	varX := code.Var{Name: "x"}
+	program := func(test *Test) {
+		test.Program.Append(
+			code.ExprAt(at(1, 1), code.Let{Decl: varX, Init: num(1)}),
+			code.ExprNew(code.Block{List: []code.Expr{
+				code.ExprAt(at(3, 5), code.Let{Decl: varX, Init: num(2)}),
+				code.ExprAt(at(4, 5), code.Let{Decl: code.Var{Name: "_x"}, Init: num(3)}),
+				code.ExprNew(code.Block{List: []code.Expr{
+					code.ExprAt(at(6, 9), code.Let{Decl: code.Var{Name: "_x"}, Init: code.ExprNew(varX)}),
+				}}),
+				code.ExprNew(code.Print{Args: []code.Expr{code.ExprNew(varX)}}),
+			}}),
+		)
+		test.ExpectStdOut = "2\n"
+	}
+
+	test := NewTest(t)
+	program(test)
+	test.Check()
+	test.Equal(0, test.Program.Errors.Len())
+
+	test = NewTest(t)
+	test.Program.WarnShadow = true
+	program(test)
+	test.Check()
+	test.False(test.Program.HasErrors())
+	test.Equal([]string{
+		"main.bit:3:5: warning: `x` shadows an outer declaration\n    main.bit:1:1: shadowed declaration",
+	}, diagnostics(test))
+}
+
+func TestUnusedWarning(t *testing.T) {
+	at := func(line, column int) base.Span {
+		return base.Span{File: "main.bit", Sta: base.Pos{Line: line, Column: column}}
+	}
+
+	test := NewTest(t)
+	test.Program.WarnUnused = true
+	types := test.Program.Types()
+	typeT := types.Var("T")
+	typeOption := types.Option(types.Scalar(code.TypeScalarNumber))
+
+	varC := code.Var{Name: "c"}
+	test.Program.Append(
+		code.ExprAt(at(1, 1), code.Func{
+			Name:     "first",
+			Generics: []code.Type{typeT},
+			Params:   []code.Var{{Name: "a", Type: typeT}, {Name: "b", Type: typeT, Span: at(1, 16)}},
+			Result:   typeT,
+			Body:     code.ExprNew(code.Var{Name: "a"}),
+		}),
+		code.ExprNew(code.Block{List: []code.Expr{
+			code.ExprAt(at(4, 5), code.Let{Decl: code.Var{Name: "a"}, Init: num(1)}),
+			code.ExprAt(at(5, 5), code.Let{Decl: code.Var{Name: "_b"}, Init: num(2)}),
+			code.ExprAt(at(6, 5), code.Let{Decl: varC, Init: call("first", num(3), num(4))}),
+			code.ExprAt(at(7, 5), code.Func{Name: "g", Body: num(1)}),
+			code.ExprAt(at(8, 5), code.Match{
+				Value: code.ExprNew(code.Variant{Type: typeOption, Name: "Some", Value: num(5)}),
+				Cases: []code.MatchCase{
+					{Variant: "Some", Bind: code.Var{Name: "v", Span: at(9, 14)}, Body: num(1)},
+					{Variant: "None", Body: num(0)},
+				},
+			}),
+			code.ExprNew(code.Print{Args: []code.Expr{code.ExprNew(varC), call("first", str("x"), str("y"))}}),
+		}}),
+	)
+
+	test.ExpectStdOut = "3 x\n"
+	test.Check()
+	test.False(test.Program.HasErrors())
+	test.Equal([]string{
+		"main.bit:1:16: warning: unused variable `b`",
+		"main.bit:4:5: warning: unused variable `a`",
+		"main.bit:7:5: warning: unused function `g`",
+		"main.bit:9:14: warning: unused variable `v`",
+	}, diagnostics(test))
+
+	for _, it := range test.Program.Errors.Diagnostics() {
+		test.Equal("W0002", it.Code)
+	}
+}
+
+// Returns the program diagnostics sorted by location.
+func diagnostics(test *Test) (out []string) {
+	test.Program.Errors.Sort()
+	for _, it := range test.Program.Errors.Diagnostics() {
+		out = append(out, it.Error())
+	}
+	return out
+}
+
+func TestCompileErrorDiagnostic(t *testing.T) {
+	test := NewTest(t)
+	span := base.Span{File: "main.bit", Sta: base.Pos{Line: 2, Column: 5}}
+	first := base.Span{File: "main.bit", Sta: base.Pos{Line: 1, Column: 5}}
+
+	varX := code.Var{Name: "x"}
+	test.Program.Append(code.ExprNew(code.Block{List: []code.Expr{
+		code.ExprAt(first, code.Let{Decl: varX, Init: num(1)}),
+		code.ExprAt(span, code.Let{Decl: varX, Init: num(2)}),
+	}}))
+
+	_, err := test.Program.Compile()
+	test.Error(err)
+
+	diag := base.AsDiagnostic(err)
+	test.Equal(base.SeverityError, diag.Severity)
+	test.Equal("E0001", diag.Code)
+	test.Equal("`x` is already declared", diag.Message)
+	test.Equal(span, diag.Span)
+	test.Equal([]base.Related{{Span: first, Message: "first declared here"}}, diag.Related)
+
+	test = NewTest(t)
+	test.Program.Append(code.ExprAt(span, code.Var{Name: "y"}))
+	_, err = test.Program.Compile()
+
+	diag = base.AsDiagnostic(err)
+	test.Equal(base.SeverityError, diag.Severity)
+	test.Equal("variable `y` not in the scope", diag.Message)
+	test.Equal(span, diag.Span)
+}
@@ -0,0 +1,113 @@
+package code_tests
+
+import (
+	"testing"
+
+	"axlab.dev/bit/code"
+)
+
+func TestReadLine(t *testing.T) {
+	test := NewTest(t)
+	test.StdIn = "first\r\nsecond\nlast\r"
+
+	bindV := code.Var{Name: "v"}
+	readLine := code.ExprNew(code.Match{
+		Value: call("read_line"),
+		Cases: []code.MatchCase{
+			{Variant: "Some", Bind: bindV, Body: stmt(code.Print{Args: []code.Expr{str("line:"), code.ExprNew(bindV)}})},
+			{Variant: "None", Body: stmt(code.Print{Args: []code.Expr{str("eof")}})},
+		},
+	})
+
+	test.Program.Append(code.ExprNew(code.Block{
+		List: []code.Expr{readLine, readLine, readLine, readLine},
+	}))
+
+	test.ExpectStdOut = "line: first\nline: second\nline: last\neof\n"
+	test.Check()
+}
+
+func TestReadAll(t *testing.T) {
+	bindV := code.Var{Name: "v"}
+	bindE := code.Var{Name: "e"}
+	readAll := code.ExprNew(code.Match{
+		Value: call("read_all"),
+		Cases: []code.MatchCase{
+			{Variant: "Ok", Bind: bindV, Body: stmt(code.Print{Args: []code.Expr{method(code.ExprNew(bindV), "len")}})},
+			{Variant: "Err", Bind: bindE, Body: stmt(code.Print{Args: []code.Expr{
+				str("error:"),
+				code.ExprNew(code.Field{Value: code.ExprNew(bindE), Name: "message"}),
+			}})},
+		},
+	})
+
+	test := NewTest(t)
+	test.StdIn = "a\nb\nc\n"
+	test.Program.Append(code.ExprNew(code.Block{
+		List: []code.Expr{
+			code.ExprNew(code.Match{
+				Value: call("read_line"),
+				Cases: []code.MatchCase{
+					{Variant: "Some", Bind: bindV, Body: stmt(code.Print{Args: []code.Expr{code.ExprNew(bindV)}})},
+					{Variant: "None", Body: stmt(code.Print{Args: []code.Expr{str("eof")}})},
+				},
+			}),
+			readAll,
+			readAll,
+		},
+	}))
+
+	test.ExpectStdOut = "a\n4\n0\n"
+	test.Check()
+
+	test = NewTest(t)
+	test.StdIn = "a\xffb"
+	test.Program.Append(readAll)
+	test.ExpectStdOut = "error: invalid UTF-8 at byte offset 1\n"
+	test.Check()
+}
+
+func TestLines(t *testing.T) {
+	test := NewTest(t)
+	test.StdIn = "one\ntwo\n\nthree\n"
+
+	varL := code.Var{Name: "l"}
+	test.Program.Append(code.ExprNew(code.For{
+		Vars:  []code.Var{varL},
+		Value: call("lines"),
+		Body:  code.ExprNew(code.Print{Args: []code.Expr{code.ExprNew(varL), method(code.ExprNew(varL), "len")}}),
+	}))
+
+	test.ExpectStdOut = "one 3\ntwo 3\n 0\nthree 5\n"
+	test.Check()
+
+	test = NewTest(t)
+	test.Program.Append(code.ExprNew(code.For{
+		Vars:  []code.Var{varL},
+		Value: call("lines"),
+		Body:  code.ExprNew(code.Print{Args: []code.Expr{code.ExprNew(varL)}}),
+	}))
+	test.Check()
+}
+
+func TestStdInErrors(t *testing.T) {
+	test := NewTest(t)
+	test.Program.Append(call("read_line", num(1)))
+	test.CheckCompileError("expected 0 arguments, got 1")
+
+	test = NewTest(t)
+	test.Program.Append(code.ExprNew(code.For{
+		Vars:  []code.Var{{Name: "a"}, {Name: "b"}},
+		Value: call("lines"),
+		Body:  code.ExprNew(code.Print{}),
+	}))
+	test.CheckCompileError("must bind one variable")
+
+	test = NewTest(t)
+	test.Program.Append(code.ExprNew(code.For{
+		Vars:  []code.Var{{Name: "a"}},
+		Value: code.ExprNew(code.Var{Name: "read_all"}),
+		Body:  code.ExprNew(code.Print{}),
+	}))
+	test.CheckCompileError("cannot iterate over function")
+}
@@ -4,6 +4,7 @@ import (
 	"strings"
 	"testing"
 
+	"axlab.dev/bit/base"
 	"axlab.dev/bit/code"
 	"github.com/stretchr/testify/require"
 )
@@ -12,6 +13,7 @@ type Test struct {
 	*require.Assertions
 
 	Program code.Program
+	StdIn   string
 
 	ExpectStdOut string
 	ExpectStdErr string
@@ -23,7 +25,37 @@ func NewTest(t *testing.T) *Test {
 	return test
 }
 
+func (test *Test) CheckCompileError(msg string) {
+	test.False(test.Program.HasErrors(), "program with errors: %s", test.Program.Errors.String())
+
+	_, err := test.Program.Compile()
+	test.ErrorContains(err, msg)
+	test.checkCode(err)
+}
+
+// Checks that the error is a diagnostic with a registered code.
+func (test *Test) checkCode(err error) {
+	diag := base.AsDiagnostic(err)
+	_, ok := code.LookupCode(diag.Code)
+	test.True(ok, "error without a registered code: %v", err)
+}
+
+func (test *Test) CheckRuntimeError(msg string) {
+	_, err := test.run()
+	test.ErrorContains(err, msg)
+	test.checkCode(err)
+}
+
 func (test *Test) Check() {
+	ans, err := test.run()
+	test.NoError(err, "program evaluation error")
+
+	if test.ExpectResult != nil {
+		test.EqualValues(test.ExpectResult, ans)
+	}
+}
+
+func (test *Test) run() (ans any, err error) {
 	if test.Program.HasErrors() {
 		test.Fail("program with errors: %s", test.Program.Errors.String())
 	}
@@ -36,10 +68,10 @@ func (test *Test) Check() {
 	rt := code.Runtime{
 		StdOut: &stdOut,
 		StdErr: &stdErr,
+		StdIn:  strings.NewReader(test.StdIn),
 	}
 
-	ans, err := eval(&rt)
-	test.NoError(err, "program evaluation error")
+	ans, err = eval(&rt)
 
 	test.Equal(test.ExpectStdOut, stdOut.String())
 
@@ -49,7 +81,5 @@ func (test *Test) Check() {
 		test.Empty(stdErr.String(), "expected no error output")
 	}
 
-	if test.ExpectResult != nil {
-		test.EqualValues(test.ExpectResult, ans)
-	}
+	return ans, err
 }
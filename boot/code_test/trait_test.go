@@ -0,0 +1,164 @@
+package code_tests
+
+import (
+	"testing"
+
+	"axlab.dev/bit/code"
+)
+
+func TestTraits(t *testing.T) {
+	test := NewTest(t)
+	program := &test.Program
+	types := program.Types()
+
+	typeNum := types.Scalar(code.TypeScalarNumber)
+	typeStr := types.Scalar(code.TypeScalarString)
+
+	show := declareShow(test)
+
+	typeA, typeB := types.Var("A", show), types.Var("B")
+	pair := types.Record("Pair", typeA, typeB)
+	test.NoError(pair.Def().(code.TypeRecord).Define(
+		code.RecordField{Name: "fst", Type: typeA},
+		code.RecordField{Name: "snd", Type: typeB},
+	))
+
+	self := func(typ code.Type) code.Var {
+		return code.Var{Name: "self", Type: typ}
+	}
+
+	showMethod := func(typ code.Type, body code.Expr) code.Func {
+		return code.Func{Name: "show", Params: []code.Var{self(typ)}, Result: typeStr, Body: body}
+	}
+
+	program.Append(
+		code.ExprNew(code.Impl{
+			Trait:   show,
+			Type:    typeNum,
+			Methods: []code.Func{showMethod(typeNum, code.ExprNew(code.Str{Value: "number"}))},
+		}),
+		code.ExprNew(code.Impl{
+			Trait:   show,
+			Type:    typeStr,
+			Methods: []code.Func{showMethod(typeStr, code.ExprNew(self(typeStr)))},
+		}),
+		code.ExprNew(code.Impl{
+			Generics: []code.Type{typeA, typeB},
+			Trait:    show,
+			Type:     pair,
+			Methods: []code.Func{showMethod(pair, showCall(show, code.ExprNew(code.Field{
+				Value: code.ExprNew(self(pair)),
+				Name:  "fst",
+			})))},
+		}),
+	)
+
+	typeT := types.Var("T", show)
+	argX := code.Var{Name: "x", Type: typeT}
+	objA := code.Var{Name: "a", Type: show}
+	objB := code.Var{Name: "b", Type: show}
+
+	program.Append(code.ExprNew(code.Block{
+		List: []code.Expr{
+			code.ExprNew(code.Func{
+				Name:     "describe",
+				Generics: []code.Type{typeT},
+				Params:   []code.Var{argX},
+				Result:   typeStr,
+				Body:     showCall(show, code.ExprNew(argX)),
+			}),
+			code.ExprNew(code.Print{
+				Args: []code.Expr{
+					call("describe", code.ExprNew(code.Number{Value: 1})),
+					call("describe", code.ExprNew(code.Str{Value: "abc"})),
+					call("describe", code.ExprNew(code.Record{
+						Type: pair,
+						Fields: []code.Expr{
+							code.ExprNew(code.Str{Value: "left"}),
+							code.ExprNew(code.Number{Value: 2}),
+						},
+					})),
+				},
+			}),
+			code.ExprNew(code.Let{Decl: objA, Init: code.ExprNew(code.Number{Value: 5})}),
+			code.ExprNew(code.Let{Decl: objB, Init: code.ExprNew(code.Str{Value: "dynamic"})}),
+			code.ExprNew(code.Print{
+				Args: []code.Expr{
+					showCall(show, code.ExprNew(objA)),
+					showCall(show, code.ExprNew(objB)),
+					call("describe", code.ExprNew(objB)),
+				},
+			}),
+		},
+	}))
+
+	test.ExpectStdOut = "number abc left\nnumber dynamic dynamic\n"
+	test.Check()
+}
+
+func TestTraitErrors(t *testing.T) {
+	test := NewTest(t)
+	types := test.Program.Types()
+
+	show := declareShow(test)
+	typeT := types.Var("T", show)
+	argX := code.Var{Name: "x", Type: typeT}
+
+	test.Program.Append(code.ExprNew(code.Block{
+		List: []code.Expr{
+			code.ExprNew(code.Func{
+				Name:     "describe",
+				Generics: []code.Type{typeT},
+				Params:   []code.Var{argX},
+				Result:   types.Scalar(code.TypeScalarString),
+				Body:     showCall(show, code.ExprNew(argX)),
+			}),
+			call("describe", code.ExprNew(code.Number{Value: 1})),
+		},
+	}))
+	test.CheckCompileError("type `Number` does not implement `Show`")
+
+	test = NewTest(t)
+	types = test.Program.Types()
+
+	show = declareShow(test)
+	test.Program.Append(code.ExprNew(code.Impl{
+		Trait: show,
+		Type:  types.Scalar(code.TypeScalarNumber),
+	}))
+	test.CheckCompileError("impl of `Show` for `Number` is missing method `show`")
+
+	test = NewTest(t)
+	types = test.Program.Types()
+
+	show = declareShow(test)
+	typeU := types.Var("U")
+	argY := code.Var{Name: "y", Type: typeU}
+	test.Program.Append(code.ExprNew(code.Block{
+		List: []code.Expr{
+			code.ExprNew(code.Func{
+				Name:     "unbound",
+				Generics: []code.Type{typeU},
+				Params:   []code.Var{argY},
+				Result:   types.Scalar(code.TypeScalarString),
+				Body:     showCall(show, code.ExprNew(argY)),
+			}),
+		},
+	}))
+	test.CheckCompileError("type `U` does not implement `Show`")
+}
+
+func declareShow(test *Test) code.Type {
+	types := test.Program.Types()
+	show := types.Trait("Show")
+	trait := show.Def().(code.TypeTrait)
+	test.NoError(trait.Define(code.TraitMethod{
+		Name: "show",
+		Type: types.Func(types.Scalar(code.TypeScalarString), trait.Self()),
+	}))
+	return show
+}
+
+func showCall(show code.Type, recv code.Expr) code.Expr {
+	return code.ExprNew(code.TraitCall{Trait: show, Method: "show", Args: []code.Expr{recv}})
+}
@@ -0,0 +1,108 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"axlab.dev/bit/base"
+)
+
+// Creates a new project in the directory with a manifest, an entry module,
+// a sample module it imports and a test module for the sample. The name
+// defaults to the directory name.
+//
+// The sample code is written in the planned source syntax, which cannot be
+// parsed yet. Since there are no assertions, the sample test only prints
+// the result it checks.
+//
+// Fails without writing anything if any of the files already exists.
+func Init(dir, name string) (*Project, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		name = projectName(filepath.Base(dir))
+	}
+	if !reName.MatchString(name) {
+		return nil, fmt.Errorf("invalid project name `%s`, use lowercase letters, digits and underscores", name)
+	}
+
+	manifest := &Manifest{
+		Name:    name,
+		Version: "0.1.0",
+		Entry:   "main",
+		Sources: []string{"src"},
+		Edition: Edition,
+	}
+
+	files := map[string]string{
+		"src/main.bit": base.Text(`
+			import greet
+
+			print(greet.hello("world"))
+		`),
+		"src/greet.bit": base.Text(`
+			pub fn hello(name: String) -> String {
+				"hello, {name}"
+			}
+		`),
+		"src/greet_test.bit": base.Text(`
+			import greet
+
+			print(greet.hello("test"))
+		`),
+	}
+
+	for _, it := range append([]string{ManifestFile}, sortedKeys(files)...) {
+		if file := filepath.Join(dir, filepath.FromSlash(it)); base.IsFile(file) {
+			return nil, fmt.Errorf("cannot initialize project: `%s` already exists", file)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "src"), 0o755); err != nil {
+		return nil, err
+	}
+
+	if err := manifest.Save(filepath.Join(dir, ManifestFile)); err != nil {
+		return nil, err
+	}
+
+	for _, it := range sortedKeys(files) {
+		if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(it)), []byte(files[it]), 0o644); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Project{Dir: dir, Manifest: manifest}, nil
+}
+
+// Derives a valid project name from a directory name.
+func projectName(dir string) string {
+	out := strings.Builder{}
+	for _, chr := range strings.ToLower(dir) {
+		if chr >= 'a' && chr <= 'z' || chr >= '0' && chr <= '9' {
+			out.WriteRune(chr)
+		} else if out.Len() > 0 {
+			out.WriteRune('_')
+		}
+	}
+
+	name := strings.TrimRight(out.String(), "_")
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		name = "app" + name
+	}
+	return name
+}
+
+func sortedKeys(files map[string]string) (out []string) {
+	for it := range files {
+		out = append(out, it)
+	}
+	sort.Strings(out)
+	return out
+}
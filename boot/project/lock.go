@@ -0,0 +1,147 @@
+package project
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Name of the lockfile at the root of a project.
+const LockFile = "bit.lock"
+
+// Directory with the resolved dependencies, one per dependency name.
+const VendorDir = "vendor"
+
+// Current lockfile format.
+const lockVersion = 1
+
+// Lockfile recording the resolved dependencies, including the ones from
+// other dependencies, with the content hash for their vendored files.
+type Lockfile struct {
+	Version int      `json:"version"`
+	Deps    []Locked `json:"dependencies"`
+}
+
+// Resolved dependency in the lockfile.
+//
+// The source is `path:` or `archive:` followed by the location, relative to
+// the project root.
+type Locked struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Source  string `json:"source"`
+	Hash    string `json:"hash"`
+}
+
+// Reads the lockfile.
+func LoadLock(file string) (*Lockfile, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, manifestError(file, "reading lockfile: %v", err)
+	}
+
+	lock := &Lockfile{}
+	if err := json.Unmarshal(data, lock); err != nil {
+		return nil, manifestError(file, "invalid lockfile: %v", err)
+	}
+	if lock.Version != lockVersion {
+		return nil, manifestError(file, "unsupported lockfile version %d", lock.Version)
+	}
+	return lock, nil
+}
+
+// Writes the lockfile, with the dependencies sorted by name.
+func (lock *Lockfile) Save(file string) error {
+	lock.Version = lockVersion
+	sort.Slice(lock.Deps, func(a, b int) bool {
+		return lock.Deps[a].Name < lock.Deps[b].Name
+	})
+
+	data, err := json.MarshalIndent(lock, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(data, '\n'), 0o644)
+}
+
+// Returns the entry for the dependency, if any.
+func (lock *Lockfile) Get(name string) (out Locked, found bool) {
+	for _, it := range lock.Deps {
+		if it.Name == name {
+			return it, true
+		}
+	}
+	return out, false
+}
+
+// Returns the content hash for the files in the directory.
+//
+// The hash covers the relative path and contents of every regular file,
+// in path order, so it does not depend on timestamps or permissions.
+// Hidden files and directories are skipped.
+func HashDir(dir string) (string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil || file == dir {
+			return err
+		}
+		if isHidden(entry.Name()) {
+			if entry.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return fmt.Errorf("unsupported file type for `%s`", file)
+		}
+
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	sort.Strings(files)
+	hash := sha256.New()
+	for _, it := range files {
+		sum, err := hashFile(filepath.Join(dir, filepath.FromSlash(it)))
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(hash, "%s\x00%s\n", it, sum)
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func hashFile(file string) (string, error) {
+	input, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer input.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, input); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func isHidden(name string) bool {
+	return strings.HasPrefix(name, ".")
+}
@@ -0,0 +1,154 @@
+package project
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"axlab.dev/bit/base"
+)
+
+// Name of the manifest file at the root of a project.
+const ManifestFile = "bit.json"
+
+// Current language edition, used for new projects.
+const Edition = "2026"
+
+// Language editions supported by the compiler.
+var Editions = []string{"2026"}
+
+// Project manifest, stored as JSON in the project root.
+//
+// The entry point is the path of the module to run, relative to the source
+// directories. Source directories are relative to the project root, as are
+// the paths for local dependencies.
+type Manifest struct {
+	Name    string                `json:"name"`
+	Version string                `json:"version"`
+	Entry   string                `json:"entry,omitempty"`
+	Sources []string              `json:"sources,omitempty"`
+	Edition string                `json:"edition"`
+	Deps    map[string]Dependency `json:"dependencies,omitempty"`
+}
+
+// Dependency on another project in the local file system, either as a
+// directory or as an archive file (`.tar`, `.tar.gz`, `.tgz` or `.zip`).
+type Dependency struct {
+	Path    string `json:"path,omitempty"`
+	Archive string `json:"archive,omitempty"`
+}
+
+var (
+	reName    = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	reVersion = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(-[0-9A-Za-z.-]+)?$`)
+)
+
+// Reads and validates the manifest file.
+func LoadManifest(file string) (*Manifest, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, manifestError(file, "reading manifest: %v", err)
+	}
+	return ParseManifest(file, data)
+}
+
+// Parses and validates the manifest data, filling in the defaults for the
+// optional fields. The file name is used for errors.
+func ParseManifest(file string, data []byte) (*Manifest, error) {
+	manifest := &Manifest{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(manifest); err != nil {
+		return nil, manifestError(file, "invalid manifest: %v", err)
+	}
+
+	if manifest.Entry == "" {
+		manifest.Entry = "main"
+	}
+	if len(manifest.Sources) == 0 {
+		manifest.Sources = []string{"src"}
+	}
+
+	if err := manifest.validate(file); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func (manifest *Manifest) validate(file string) error {
+	errs := base.ErrorSet{}
+	check := func(valid bool, msg string, args ...any) {
+		if !valid {
+			errs.Add(manifestError(file, msg, args...))
+		}
+	}
+
+	check(reName.MatchString(manifest.Name), "invalid project name `%s`", manifest.Name)
+	check(reVersion.MatchString(manifest.Version), "invalid version `%s`, expected `major.minor.patch`", manifest.Version)
+	check(isRelativePath(manifest.Entry) && path.Clean(manifest.Entry) != ".", "invalid entry module `%s`", manifest.Entry)
+	check(manifest.Edition != "", "missing language edition")
+	check(manifest.Edition == "" || isEdition(manifest.Edition), "unsupported edition `%s`, expected one of %s", manifest.Edition, strings.Join(Editions, ", "))
+
+	for _, it := range manifest.Sources {
+		check(isRelativePath(it), "invalid source directory `%s`", it)
+	}
+
+	for _, name := range manifest.DepNames() {
+		dep := manifest.Deps[name]
+		check(reName.MatchString(name), "invalid dependency name `%s`", name)
+		check((dep.Path == "") != (dep.Archive == ""), "dependency `%s` must have either a path or an archive", name)
+		check(dep.Archive == "" || archiveKind(dep.Archive) != "", "unsupported archive `%s` for dependency `%s`", dep.Archive, name)
+	}
+
+	return base.Errors(errs.Errors()...)
+}
+
+// Returns the dependency names in sorted order.
+func (manifest *Manifest) DepNames() (out []string) {
+	for name := range manifest.Deps {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Writes the manifest file, formatted.
+func (manifest *Manifest) Save(file string) error {
+	data, err := json.MarshalIndent(manifest, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(data, '\n'), 0o644)
+}
+
+func manifestError(file, msg string, args ...any) error {
+	return &base.Diagnostic{
+		Message: fmt.Sprintf(msg, args...),
+		Span:    FileSpan(file),
+	}
+}
+
+// Returns a span for diagnostics about a whole file, at its start.
+func FileSpan(file string) base.Span {
+	return base.Span{File: file, Sta: base.Pos{Line: 1, Column: 1}}
+}
+
+// Paths in the manifest use `/` and must stay inside the project.
+func isRelativePath(name string) bool {
+	clean := path.Clean(name)
+	return name != "" && !path.IsAbs(clean) && clean != ".." && !strings.HasPrefix(clean, "../") && !strings.Contains(name, `\`)
+}
+
+func isEdition(name string) bool {
+	for _, it := range Editions {
+		if it == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,182 @@
+package project
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"axlab.dev/bit/base"
+	"axlab.dev/bit/code"
+)
+
+// Project loaded from its manifest.
+type Project struct {
+	Dir      string
+	Manifest *Manifest
+}
+
+// Source file for a module in the project.
+type Source struct {
+	Module string
+	File   string
+}
+
+// Suffix for the path of test modules.
+const TestSuffix = "_test"
+
+// Returns the nearest directory containing a manifest, walking up from the
+// start directory.
+func Find(start string) (dir string, found bool) {
+	return base.FindDir(start, func(dir string) bool {
+		return base.IsFile(filepath.Join(dir, ManifestFile))
+	})
+}
+
+// Opens the project containing the directory.
+func Open(start string) (*Project, error) {
+	start, err := filepath.Abs(start)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, found := Find(start)
+	if !found {
+		return nil, fmt.Errorf("no `%s` found in `%s` or its parent directories", ManifestFile, start)
+	}
+	return Load(dir)
+}
+
+// Loads the project in the directory.
+func Load(dir string) (*Project, error) {
+	manifest, err := LoadManifest(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	return &Project{Dir: dir, Manifest: manifest}, nil
+}
+
+func (project *Project) ManifestFile() string {
+	return filepath.Join(project.Dir, ManifestFile)
+}
+
+// Lists the modules in the source directories, sorted by path. Module paths
+// are relative to their source directory.
+func (project *Project) Modules() (out []Source, err error) {
+	errs := base.ErrorSet{}
+	files := make(map[string]string)
+	for _, src := range project.Manifest.Sources {
+		root := filepath.Join(project.Dir, filepath.FromSlash(src))
+		if !base.IsDir(root) {
+			errs.Add(project.errorf("source directory `%s` not found", src))
+			continue
+		}
+
+		err := filepath.WalkDir(root, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() || filepath.Ext(file) != code.ModuleExt {
+				return err
+			}
+
+			rel, err := filepath.Rel(root, file)
+			if err != nil {
+				return err
+			}
+
+			module := strings.TrimSuffix(filepath.ToSlash(rel), code.ModuleExt)
+			if first, dup := files[module]; dup {
+				errs.Add(&base.Diagnostic{
+					Message: fmt.Sprintf("module `%s` is defined more than once", module),
+					Span:    FileSpan(file),
+					Related: []base.Related{{Span: FileSpan(first), Message: "first defined here"}},
+				})
+				return nil
+			}
+
+			files[module] = file
+			out = append(out, Source{Module: module, File: file})
+			return nil
+		})
+		errs.Add(err)
+	}
+
+	sort.Slice(out, func(a, b int) bool {
+		return out[a].Module < out[b].Module
+	})
+	return out, base.Errors(errs.Errors()...)
+}
+
+// Checks the project layout, returning the modules in the project and its
+// vendored dependencies. The entry module must exist and the dependencies
+// must match the lockfile.
+//
+// Modules from a dependency are prefixed with its name. Test modules from
+// dependencies are not included.
+func (project *Project) Check() (modules []Source, err error) {
+	errs := base.ErrorSet{}
+
+	modules, err = project.Modules()
+	errs.Add(err)
+
+	entry := project.Manifest.Entry
+	if err == nil && !hasModule(modules, entry) {
+		errs.Add(project.errorf("entry module `%s` not found", entry))
+	}
+
+	lock, err := project.Verify()
+	if err != nil {
+		errs.Add(err)
+		return modules, base.Errors(errs.Errors()...)
+	}
+
+	for _, it := range lock.Deps {
+		dep, err := Load(filepath.Join(project.Dir, VendorDir, it.Name))
+		if err != nil {
+			errs.Add(err)
+			continue
+		}
+
+		depModules, err := dep.Modules()
+		errs.Add(err)
+		for _, mod := range depModules {
+			if strings.HasSuffix(mod.Module, TestSuffix) {
+				continue
+			}
+
+			mod.Module = it.Name + "/" + mod.Module
+			if hasModule(modules, mod.Module) {
+				errs.Add(project.errorf("module `%s` conflicts with dependency `%s`", mod.Module, it.Name))
+				continue
+			}
+			modules = append(modules, mod)
+		}
+	}
+
+	sort.Slice(modules, func(a, b int) bool {
+		return modules[a].Module < modules[b].Module
+	})
+	return modules, base.Errors(errs.Errors()...)
+}
+
+// Returns the test modules.
+func Tests(modules []Source) (out []Source) {
+	for _, it := range modules {
+		if strings.HasSuffix(it.Module, TestSuffix) {
+			out = append(out, it)
+		}
+	}
+	return out
+}
+
+func hasModule(modules []Source, name string) bool {
+	for _, it := range modules {
+		if it.Module == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (project *Project) errorf(msg string, args ...any) error {
+	return manifestError(project.ManifestFile(), msg, args...)
+}
@@ -0,0 +1,102 @@
+package project_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"axlab.dev/bit/project"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseManifest(t *testing.T) {
+	test := require.New(t)
+
+	manifest, err := project.ParseManifest("bit.json", []byte(`{
+		"name": "app",
+		"version": "1.2.3",
+		"edition": "2026",
+		"dependencies": {"util": {"path": "../util"}}
+	}`))
+	test.NoError(err)
+	test.Equal("main", manifest.Entry)
+	test.Equal([]string{"src"}, manifest.Sources)
+	test.Equal([]string{"util"}, manifest.DepNames())
+
+	_, err = project.ParseManifest("bit.json", []byte(`{
+		"name": "App",
+		"version": "1.2",
+		"entry": "../main",
+		"sources": ["/src"],
+		"edition": "1999"
+	}`))
+	test.ErrorContains(err, "bit.json:1:1: invalid project name `App`")
+	test.ErrorContains(err, "invalid version `1.2`")
+	test.ErrorContains(err, "invalid entry module `../main`")
+	test.ErrorContains(err, "invalid source directory `/src`")
+	test.ErrorContains(err, "unsupported edition `1999`")
+
+	_, err = project.ParseManifest("bit.json", []byte(`{"name": "app", "other": 1}`))
+	test.ErrorContains(err, "unknown field \"other\"")
+}
+
+func TestInitAndOpen(t *testing.T) {
+	test := require.New(t)
+
+	dir := filepath.Join(t.TempDir(), "My-App")
+	created, err := project.Init(dir, "")
+	test.NoError(err)
+	test.Equal("my_app", created.Manifest.Name)
+
+	_, err = project.Init(dir, "")
+	test.ErrorContains(err, "already exists")
+
+	opened, err := project.Open(filepath.Join(dir, "src"))
+	test.NoError(err)
+	test.Equal(dir, opened.Dir)
+	test.Equal(created.Manifest, opened.Manifest)
+
+	modules, err := opened.Check()
+	test.NoError(err)
+	test.Equal([]project.Source{
+		{Module: "greet", File: filepath.Join(dir, "src", "greet.bit")},
+		{Module: "greet_test", File: filepath.Join(dir, "src", "greet_test.bit")},
+		{Module: "main", File: filepath.Join(dir, "src", "main.bit")},
+	}, modules)
+	test.Equal([]project.Source{
+		{Module: "greet_test", File: filepath.Join(dir, "src", "greet_test.bit")},
+	}, project.Tests(modules))
+
+	_, err = project.Open(t.TempDir())
+	test.ErrorContains(err, "no `bit.json` found")
+}
+
+func TestCheckProject(t *testing.T) {
+	test := require.New(t)
+
+	root := t.TempDir()
+	app, err := project.Init(filepath.Join(root, "app"), "")
+	test.NoError(err)
+	_, err = project.Init(filepath.Join(root, "lib"), "library")
+	test.NoError(err)
+
+	app.Manifest.Entry = "missing"
+	app.Manifest.Sources = append(app.Manifest.Sources, "other")
+	app.Manifest.Deps = map[string]project.Dependency{
+		"lib": {Path: "../lib"},
+	}
+	test.NoError(os.MkdirAll(filepath.Join(app.Dir, "other"), 0o755))
+	test.NoError(os.WriteFile(filepath.Join(app.Dir, "other", "greet.bit"), nil, 0o644))
+
+	_, err = app.Check()
+	test.ErrorContains(err, "module `greet` is defined more than once")
+	test.ErrorContains(err, "missing `bit.lock`, run `bit vendor`")
+
+	_, err = app.Vendor(false)
+	test.ErrorContains(err, "dependency `lib` from `path:../lib` is named `library`")
+
+	test.NoError(os.Remove(filepath.Join(app.Dir, "other", "greet.bit")))
+	app.Manifest.Deps = nil
+	_, err = app.Check()
+	test.EqualError(err, app.ManifestFile()+":1:1: entry module `missing` not found")
+}
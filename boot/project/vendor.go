@@ -0,0 +1,457 @@
+package project
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"axlab.dev/bit/base"
+)
+
+// Resolves the dependencies into the vendor directory and writes the
+// lockfile. Dependencies are only read from the local file system.
+//
+// Dependencies of a dependency are resolved relative to its location and
+// vendored along with it, so each name must come from a single source.
+//
+// If the lockfile already has a dependency from the same source, its hash
+// must match the new content unless update is set.
+//
+// The new vendor directory and lockfile are written next to the current
+// ones and then swapped in by renaming, so on any error both are left
+// unchanged.
+func (project *Project) Vendor(update bool) (*Lockfile, error) {
+	old := &Lockfile{}
+	if lockFile := project.LockFile(); base.IsFile(lockFile) {
+		var err error
+		if old, err = LoadLock(lockFile); err != nil {
+			return nil, err
+		}
+	}
+
+	staging := filepath.Join(project.Dir, ".vendor.new")
+	if err := os.RemoveAll(staging); err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(staging)
+
+	// dependencies are copied or extracted here first, since the root for
+	// an archive may be a directory inside it
+	extracted := filepath.Join(staging, ".extract")
+	if err := os.MkdirAll(extracted, 0o755); err != nil {
+		return nil, err
+	}
+
+	type pending struct {
+		name string
+		dep  Dependency
+		dir  string
+	}
+
+	var queue []pending
+	for _, name := range project.Manifest.DepNames() {
+		queue = append(queue, pending{name, project.Manifest.Deps[name], project.Dir})
+	}
+
+	lock := &Lockfile{Version: lockVersion}
+	roots := make(map[string]string)
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+
+		source, location := project.depSource(next.dir, next.dep)
+		if locked, found := lock.Get(next.name); found {
+			if locked.Source != source {
+				return nil, project.errorf("dependency `%s` is required from both `%s` and `%s`", next.name, locked.Source, source)
+			}
+			continue
+		}
+
+		root, err := stageDep(location, next.dep, filepath.Join(extracted, next.name))
+		if err != nil {
+			return nil, project.errorf("resolving dependency `%s`: %v", next.name, err)
+		}
+
+		manifest, err := LoadManifest(filepath.Join(root, ManifestFile))
+		if err != nil {
+			return nil, err
+		}
+		if manifest.Name != next.name {
+			return nil, project.errorf("dependency `%s` from `%s` is named `%s`", next.name, source, manifest.Name)
+		}
+
+		hash, err := HashDir(root)
+		if err != nil {
+			return nil, err
+		}
+
+		if locked, found := old.Get(next.name); found && locked.Source == source && locked.Hash != hash && !update {
+			return nil, manifestError(project.LockFile(),
+				"content hash for dependency `%s` does not match `%s`: expected %s, got %s (use `bit vendor --update` to accept the change)",
+				next.name, LockFile, locked.Hash, hash)
+		}
+
+		lock.Deps = append(lock.Deps, Locked{Name: next.name, Version: manifest.Version, Source: source, Hash: hash})
+		roots[next.name] = root
+
+		nested := location
+		if next.dep.Archive != "" {
+			nested = filepath.Dir(location)
+		}
+		for _, name := range manifest.DepNames() {
+			queue = append(queue, pending{name, manifest.Deps[name], nested})
+		}
+	}
+
+	for name, root := range roots {
+		if err := os.Rename(root, filepath.Join(staging, name)); err != nil {
+			return nil, err
+		}
+	}
+	if err := os.RemoveAll(extracted); err != nil {
+		return nil, err
+	}
+
+	lockFile := project.LockFile() + ".new"
+	defer os.Remove(lockFile)
+	if err := lock.Save(lockFile); err != nil {
+		return nil, err
+	}
+
+	if err := project.replaceVendor(staging, lockFile); err != nil {
+		return nil, err
+	}
+	return lock, nil
+}
+
+// Replaces the vendor directory and the lockfile with the new ones. If the
+// lockfile cannot be replaced, the previous vendor directory is restored.
+func (project *Project) replaceVendor(staging, lockFile string) error {
+	vendor := filepath.Join(project.Dir, VendorDir)
+	previous := filepath.Join(project.Dir, ".vendor.old")
+	if err := os.RemoveAll(previous); err != nil {
+		return err
+	}
+
+	hasVendor := base.IsDir(vendor)
+	if hasVendor {
+		if err := os.Rename(vendor, previous); err != nil {
+			return err
+		}
+	}
+
+	restore := func(err error) error {
+		os.RemoveAll(vendor)
+		if hasVendor {
+			os.Rename(previous, vendor)
+		}
+		return err
+	}
+
+	if err := os.Rename(staging, vendor); err != nil {
+		return restore(err)
+	}
+	if err := os.Rename(lockFile, project.LockFile()); err != nil {
+		return restore(err)
+	}
+
+	os.RemoveAll(previous)
+	return nil
+}
+
+// Checks the vendored dependencies against the lockfile.
+//
+// Every dependency in the manifest must be locked from the same source, and
+// the files in the vendor directory must match the locked hashes.
+func (project *Project) Verify() (*Lockfile, error) {
+	lockFile := project.LockFile()
+	if len(project.Manifest.Deps) == 0 && !base.IsFile(lockFile) {
+		return &Lockfile{Version: lockVersion}, nil
+	}
+
+	if !base.IsFile(lockFile) {
+		return nil, project.errorf("missing `%s`, run `bit vendor` to resolve the dependencies", LockFile)
+	}
+
+	lock, err := LoadLock(lockFile)
+	if err != nil {
+		return nil, err
+	}
+
+	errs := base.ErrorSet{}
+	for _, name := range project.Manifest.DepNames() {
+		source, _ := project.depSource(project.Dir, project.Manifest.Deps[name])
+		if locked, found := lock.Get(name); !found {
+			errs.Add(manifestError(lockFile, "dependency `%s` is not locked, run `bit vendor`", name))
+		} else if locked.Source != source {
+			errs.Add(manifestError(lockFile, "dependency `%s` is locked from `%s` instead of `%s`, run `bit vendor`", name, locked.Source, source))
+		}
+	}
+
+	for _, it := range lock.Deps {
+		dir := filepath.Join(project.Dir, VendorDir, it.Name)
+		if !base.IsDir(dir) {
+			errs.Add(manifestError(lockFile, "dependency `%s` is missing from `%s`, run `bit vendor`", it.Name, VendorDir))
+			continue
+		}
+
+		hash, err := HashDir(dir)
+		if err != nil {
+			errs.Add(err)
+		} else if hash != it.Hash {
+			errs.Add(manifestError(lockFile, "content hash for dependency `%s` does not match `%s`: expected %s, got %s", it.Name, LockFile, it.Hash, hash))
+		}
+	}
+
+	if err := base.Errors(errs.Errors()...); err != nil {
+		return nil, err
+	}
+	return lock, nil
+}
+
+func (project *Project) LockFile() string {
+	return filepath.Join(project.Dir, LockFile)
+}
+
+// Returns the source for the lockfile and the file system location of a
+// dependency declared in the given directory.
+func (project *Project) depSource(dir string, dep Dependency) (source, location string) {
+	kind, name := "path", dep.Path
+	if dep.Archive != "" {
+		kind, name = "archive", dep.Archive
+	}
+
+	location = filepath.FromSlash(name)
+	if !filepath.IsAbs(location) {
+		location = filepath.Join(dir, location)
+	}
+
+	if rel, err := filepath.Rel(project.Dir, location); err == nil {
+		return kind + ":" + filepath.ToSlash(rel), location
+	}
+	return kind + ":" + filepath.ToSlash(location), location
+}
+
+// Copies or extracts the dependency into the directory, returning the root
+// directory for the dependency project.
+//
+// Archives may contain the project files directly or inside a single
+// top-level directory.
+func stageDep(location string, dep Dependency, dir string) (root string, err error) {
+	if dep.Archive != "" {
+		if !base.IsFile(location) {
+			return "", fmt.Errorf("archive `%s` not found", location)
+		}
+		err = extractArchive(location, dir)
+	} else {
+		if !base.IsDir(location) {
+			return "", fmt.Errorf("directory `%s` not found", location)
+		}
+		err = copyDir(location, dir)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if base.IsFile(filepath.Join(dir, ManifestFile)) {
+		return dir, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 1 && entries[0].IsDir() {
+		if inner := filepath.Join(dir, entries[0].Name()); base.IsFile(filepath.Join(inner, ManifestFile)) {
+			return inner, nil
+		}
+	}
+	return "", fmt.Errorf("no `%s` found in `%s`", ManifestFile, location)
+}
+
+// Copies a project directory, skipping hidden files and its own vendor
+// directory.
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, file)
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dst, rel)
+		switch {
+		case file == src:
+			return os.MkdirAll(dst, 0o755)
+		case isHidden(entry.Name()) || rel == VendorDir:
+			if entry.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		case entry.IsDir():
+			return os.MkdirAll(target, 0o755)
+		case !entry.Type().IsRegular():
+			return fmt.Errorf("unsupported file type for `%s`", file)
+		}
+
+		input, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer input.Close()
+		return writeFile(target, input)
+	})
+}
+
+// Returns the archive format from the file name: `zip`, `tar` or `tgz`.
+func archiveKind(name string) string {
+	name = strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		return "zip"
+	case strings.HasSuffix(name, ".tar"):
+		return "tar"
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return "tgz"
+	}
+	return ""
+}
+
+// Extracts the archive into the directory. Entries must be regular files or
+// directories inside the target directory. Hidden entries are skipped.
+func extractArchive(file, dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	if archiveKind(file) == "zip" {
+		archive, err := zip.OpenReader(file)
+		if err != nil {
+			return err
+		}
+		defer archive.Close()
+
+		for _, it := range archive.File {
+			kind := entryOther
+			if mode := it.Mode(); mode.IsDir() {
+				kind = entryDir
+			} else if mode.IsRegular() {
+				kind = entryFile
+			}
+			if err := extractEntry(dir, it.Name, kind, it.Open); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	input, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	var reader io.Reader = input
+	if archiveKind(file) == "tgz" {
+		unzip, err := gzip.NewReader(input)
+		if err != nil {
+			return err
+		}
+		defer unzip.Close()
+		reader = unzip
+	}
+
+	archive := tar.NewReader(reader)
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		// the type is checked from the header, since the file mode for
+		// links and other special entries may still look like a file
+		kind := entryOther
+		switch header.Typeflag {
+		case tar.TypeXGlobalHeader:
+			continue
+		case tar.TypeDir:
+			kind = entryDir
+		case tar.TypeReg:
+			kind = entryFile
+		}
+
+		open := func() (io.ReadCloser, error) {
+			return io.NopCloser(archive), nil
+		}
+		if err := extractEntry(dir, header.Name, kind, open); err != nil {
+			return err
+		}
+	}
+}
+
+// Type of an archive entry. Only files and directories can be extracted.
+type entryKind int
+
+const (
+	entryOther entryKind = iota
+	entryFile
+	entryDir
+)
+
+func extractEntry(dir, name string, kind entryKind, open func() (io.ReadCloser, error)) error {
+	clean := path.Clean(strings.TrimSuffix(name, "/"))
+	if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") || strings.Contains(name, `\`) {
+		return fmt.Errorf("invalid archive entry `%s`", name)
+	}
+
+	for _, it := range strings.Split(clean, "/") {
+		if isHidden(it) {
+			return nil
+		}
+	}
+
+	target := filepath.Join(dir, filepath.FromSlash(clean))
+	switch kind {
+	case entryDir:
+		return os.MkdirAll(target, 0o755)
+	case entryOther:
+		return fmt.Errorf("unsupported archive entry `%s`", name)
+	}
+
+	input, err := open()
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	return writeFile(target, input)
+}
+
+func writeFile(file string, input io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+
+	output, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(output, input); err != nil {
+		output.Close()
+		return err
+	}
+	return output.Close()
+}
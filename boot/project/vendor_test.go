@@ -0,0 +1,212 @@
+package project_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"axlab.dev/bit/project"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVendor(t *testing.T) {
+	test := require.New(t)
+
+	root := t.TempDir()
+	app, err := project.Init(filepath.Join(root, "app"), "")
+	test.NoError(err)
+
+	// path dependency with a nested archive dependency next to it
+	lib, err := project.Init(filepath.Join(root, "lib"), "")
+	test.NoError(err)
+	lib.Manifest.Deps = map[string]project.Dependency{"text": {Archive: "../text.tar.gz"}}
+	test.NoError(lib.Manifest.Save(lib.ManifestFile()))
+	test.NoError(os.WriteFile(filepath.Join(lib.Dir, ".hidden"), []byte("skipped"), 0o644))
+	writeTarGz(test, filepath.Join(root, "text.tar.gz"), map[string]string{
+		"text-1.0/bit.json":    `{"name": "text", "version": "1.0.0", "edition": "2026"}`,
+		"text-1.0/src/fmt.bit": "pub fn fmt() = 1\n",
+	})
+	writeZip(test, filepath.Join(root, "util.zip"), map[string]string{
+		"bit.json":          `{"name": "util", "version": "0.2.0", "edition": "2026"}`,
+		"src/util.bit":      "pub fn util() = 1\n",
+		"src/util_test.bit": "",
+	})
+
+	app.Manifest.Deps = map[string]project.Dependency{
+		"lib":  {Path: "../lib"},
+		"util": {Archive: "../util.zip"},
+	}
+	test.NoError(app.Manifest.Save(app.ManifestFile()))
+
+	lock, err := app.Vendor(false)
+	test.NoError(err)
+	test.Len(lock.Deps, 3)
+	for n, it := range []struct{ name, version, source string }{
+		{"lib", "0.1.0", "path:../lib"},
+		{"text", "1.0.0", "archive:../text.tar.gz"},
+		{"util", "0.2.0", "archive:../util.zip"},
+	} {
+		test.Equal(it.name, lock.Deps[n].Name)
+		test.Equal(it.version, lock.Deps[n].Version)
+		test.Equal(it.source, lock.Deps[n].Source)
+		test.True(strings.HasPrefix(lock.Deps[n].Hash, "sha256:"))
+	}
+
+	test.FileExists(filepath.Join(app.Dir, "vendor", "text", "src", "fmt.bit"))
+	test.NoFileExists(filepath.Join(app.Dir, "vendor", "lib", ".hidden"))
+
+	saved, err := project.LoadLock(app.LockFile())
+	test.NoError(err)
+	test.Equal(lock, saved)
+
+	modules, err := app.Check()
+	test.NoError(err)
+	var names []string
+	for _, it := range modules {
+		names = append(names, it.Module)
+	}
+	// test modules from dependencies are not included
+	test.Equal([]string{"greet", "greet_test", "lib/greet", "lib/main", "main", "text/fmt", "util/util"}, names)
+
+	// vendoring again from unchanged sources keeps the same lock
+	again, err := app.Vendor(false)
+	test.NoError(err)
+	test.Equal(lock, again)
+}
+
+func TestVendorHashMismatch(t *testing.T) {
+	test := require.New(t)
+
+	root := t.TempDir()
+	app, err := project.Init(filepath.Join(root, "app"), "")
+	test.NoError(err)
+	lib, err := project.Init(filepath.Join(root, "lib"), "")
+	test.NoError(err)
+
+	app.Manifest.Deps = map[string]project.Dependency{"lib": {Path: "../lib"}}
+	lock, err := app.Vendor(false)
+	test.NoError(err)
+
+	// changes to the vendored files fail the build
+	vendored := filepath.Join(app.Dir, "vendor", "lib", "src", "greet.bit")
+	test.NoError(os.WriteFile(vendored, []byte("changed"), 0o644))
+	_, err = app.Check()
+	test.ErrorContains(err, "content hash for dependency `lib` does not match `bit.lock`: expected "+lock.Deps[0].Hash)
+
+	// changes to the source require an explicit update
+	test.NoError(os.WriteFile(filepath.Join(lib.Dir, "src", "greet.bit"), []byte("changed"), 0o644))
+	_, err = app.Vendor(false)
+	test.ErrorContains(err, "use `bit vendor --update` to accept the change")
+
+	updated, err := app.Vendor(true)
+	test.NoError(err)
+	test.NotEqual(lock.Deps[0].Hash, updated.Deps[0].Hash)
+	_, err = app.Check()
+	test.NoError(err)
+
+	// failing to replace the lockfile keeps the previous vendor directory
+	hash := updated.Deps[0].Hash
+	test.NoError(os.Remove(app.LockFile()))
+	test.NoError(os.MkdirAll(filepath.Join(app.LockFile(), "blocked"), 0o755))
+	test.NoError(os.WriteFile(filepath.Join(lib.Dir, "src", "greet.bit"), []byte("changed again"), 0o644))
+	_, err = app.Vendor(true)
+	test.Error(err)
+	current, err := project.HashDir(filepath.Join(app.Dir, "vendor", "lib"))
+	test.NoError(err)
+	test.Equal(hash, current)
+	test.NoError(os.RemoveAll(app.LockFile()))
+	_, err = app.Vendor(true)
+	test.NoError(err)
+
+	// removed dependencies are removed from the vendor directory
+	app.Manifest.Deps = map[string]project.Dependency{"other": {Path: "../lib"}}
+	_, err = app.Check()
+	test.ErrorContains(err, "dependency `other` is not locked")
+
+	app.Manifest.Deps = nil
+	lock, err = app.Vendor(false)
+	test.NoError(err)
+	test.Empty(lock.Deps)
+	test.NoDirExists(filepath.Join(app.Dir, "vendor", "lib"))
+}
+
+func TestVendorInvalidArchive(t *testing.T) {
+	test := require.New(t)
+
+	root := t.TempDir()
+	app, err := project.Init(filepath.Join(root, "app"), "")
+	test.NoError(err)
+
+	writeTarGz(test, filepath.Join(root, "bad.tgz"), map[string]string{
+		"../escape.bit": "",
+	})
+	app.Manifest.Deps = map[string]project.Dependency{"bad": {Archive: "../bad.tgz"}}
+	_, err = app.Vendor(false)
+	test.ErrorContains(err, "invalid archive entry `../escape.bit`")
+	test.NoFileExists(filepath.Join(root, "escape.bit"))
+	test.NoFileExists(app.LockFile())
+
+	// links are rejected, even if their file mode looks like a regular file
+	links, err := os.Create(filepath.Join(root, "links.tar"))
+	test.NoError(err)
+	archive := tar.NewWriter(links)
+	test.NoError(archive.WriteHeader(&tar.Header{Name: "bit.json", Mode: 0o644, Typeflag: tar.TypeReg}))
+	test.NoError(archive.WriteHeader(&tar.Header{Name: "copy.json", Linkname: "bit.json", Mode: 0o644, Typeflag: tar.TypeLink}))
+	test.NoError(archive.Close())
+	test.NoError(links.Close())
+
+	app.Manifest.Deps = map[string]project.Dependency{"links": {Archive: "../links.tar"}}
+	_, err = app.Vendor(false)
+	test.ErrorContains(err, "unsupported archive entry `copy.json`")
+
+	app.Manifest.Deps = map[string]project.Dependency{"none": {Archive: "../none.zip"}}
+	_, err = app.Vendor(false)
+	test.ErrorContains(err, "archive `"+filepath.Join(root, "none.zip")+"` not found")
+
+	_, err = project.ParseManifest("bit.json", []byte(`{
+		"name": "app",
+		"version": "1.0.0",
+		"edition": "2026",
+		"dependencies": {
+			"both": {"path": "a", "archive": "a.zip"},
+			"rar": {"archive": "a.rar"}
+		}
+	}`))
+	test.ErrorContains(err, "dependency `both` must have either a path or an archive")
+	test.ErrorContains(err, "unsupported archive `a.rar` for dependency `rar`")
+}
+
+func writeTarGz(test *require.Assertions, file string, files map[string]string) {
+	output, err := os.Create(file)
+	test.NoError(err)
+	defer output.Close()
+
+	compressed := gzip.NewWriter(output)
+	archive := tar.NewWriter(compressed)
+	for name, text := range files {
+		test.NoError(archive.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(text)), Typeflag: tar.TypeReg}))
+		_, err := archive.Write([]byte(text))
+		test.NoError(err)
+	}
+	test.NoError(archive.Close())
+	test.NoError(compressed.Close())
+}
+
+func writeZip(test *require.Assertions, file string, files map[string]string) {
+	output, err := os.Create(file)
+	test.NoError(err)
+	defer output.Close()
+
+	archive := zip.NewWriter(output)
+	for name, text := range files {
+		entry, err := archive.Create(name)
+		test.NoError(err)
+		_, err = entry.Write([]byte(text))
+		test.NoError(err)
+	}
+	test.NoError(archive.Close())
+}